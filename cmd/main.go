@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
@@ -37,27 +39,207 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Get configuration from environment
-	baseURL := os.Getenv("UNIFI_BASE_URL")
-	if baseURL == "" {
-		baseURL = "https://192.168.1.1"
+	// Read-only mode hides write tools and blocks writes in the client itself
+	readOnly := os.Getenv("MCP_READ_ONLY") == "true"
+	if readOnly {
+		logrus.Info("Read-only mode enabled - no changes will be sent to the controller")
 	}
 
-	apiKey := os.Getenv("UNIFI_API_KEY")
-	if apiKey == "" {
-		logrus.Fatal("UNIFI_API_KEY environment variable is required")
+	// Audit log of every write sent to the controller
+	auditConfig := mcp.AuditConfig{File: os.Getenv("MCP_AUDIT_FILE"), MaxFiles: 5}
+	if maxSize := os.Getenv("MCP_AUDIT_MAX_SIZE_MB"); maxSize != "" {
+		mb, err := strconv.Atoi(maxSize)
+		if err != nil || mb < 0 {
+			logrus.Fatal("MCP_AUDIT_MAX_SIZE_MB must be a non-negative number")
+		}
+		auditConfig.MaxBytes = int64(mb) << 20
+	}
+	if maxFiles := os.Getenv("MCP_AUDIT_MAX_FILES"); maxFiles != "" {
+		n, err := strconv.Atoi(maxFiles)
+		if err != nil || n < 1 {
+			logrus.Fatal("MCP_AUDIT_MAX_FILES must be a positive number")
+		}
+		auditConfig.MaxFiles = n
+	}
+	auditLog, err := mcp.NewAuditLog(auditConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to open audit log")
+	}
+	defer auditLog.Close()
+	if auditConfig.File != "" {
+		logrus.WithField("file", auditConfig.File).Info("Audit log enabled")
 	}
 
-	// Check for SSL verification flag (default is to verify)
-	skipSSLVerify := os.Getenv("UNIFI_SKIP_SSL_VERIFY") == "true"
-	if skipSSLVerify {
-		logrus.Warn("SSL verification disabled - only use for self-signed certificates")
+	// How long a successful credential check is trusted
+	authTTL := unifi.DefaultAuthTTL
+	if ttl := os.Getenv("UNIFI_AUTH_TTL"); ttl != "" {
+		authTTL, err = time.ParseDuration(ttl)
+		if err != nil || authTTL < 0 {
+			logrus.Fatal("UNIFI_AUTH_TTL must be a duration such as 5m")
+		}
 	}
 
-	networkClient := unifi.NewNetworkClient(baseURL, apiKey, skipSSLVerify)
+	// Retries of requests that fail transiently (writes only when opted in)
+	retryPolicy := unifi.DefaultRetryPolicy()
+	if attempts := os.Getenv("UNIFI_RETRY_MAX_ATTEMPTS"); attempts != "" {
+		n, err := strconv.Atoi(attempts)
+		if err != nil || n < 1 {
+			logrus.Fatal("UNIFI_RETRY_MAX_ATTEMPTS must be a positive number")
+		}
+		retryPolicy.MaxAttempts = n
+	}
+	if delay := os.Getenv("UNIFI_RETRY_BASE_DELAY"); delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil || d <= 0 {
+			logrus.Fatal("UNIFI_RETRY_BASE_DELAY must be a positive duration such as 500ms")
+		}
+		retryPolicy.BaseDelay = d
+	}
+	if delay := os.Getenv("UNIFI_RETRY_MAX_DELAY"); delay != "" {
+		d, err := time.ParseDuration(delay)
+		if err != nil || d <= 0 {
+			logrus.Fatal("UNIFI_RETRY_MAX_DELAY must be a positive duration such as 10s")
+		}
+		retryPolicy.MaxDelay = d
+	}
+	retryPolicy.RetryWrites = os.Getenv("UNIFI_RETRY_WRITES") == "true"
 
-	// Initialize MCP server
-	server := mcp.NewServer(networkClient)
+	// Outbound connections: proxy, timeouts and connection pool
+	transportConfig := unifi.DefaultTransportConfig()
+	defaultProxy, err := unifi.ParseProxyURL(os.Getenv("UNIFI_PROXY_URL"))
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid UNIFI_PROXY_URL")
+	}
+	durationFromEnv("UNIFI_TIMEOUT", &transportConfig.Timeout)
+	durationFromEnv("UNIFI_DIAL_TIMEOUT", &transportConfig.DialTimeout)
+	durationFromEnv("UNIFI_TLS_HANDSHAKE_TIMEOUT", &transportConfig.TLSHandshakeTimeout)
+	durationFromEnv("UNIFI_RESPONSE_HEADER_TIMEOUT", &transportConfig.ResponseHeaderTimeout)
+	durationFromEnv("UNIFI_IDLE_CONN_TIMEOUT", &transportConfig.IdleConnTimeout)
+	intFromEnv("UNIFI_MAX_IDLE_CONNS", &transportConfig.MaxIdleConns)
+	intFromEnv("UNIFI_MAX_IDLE_CONNS_PER_HOST", &transportConfig.MaxIdleConnsPerHost)
+	durationFromEnv("UNIFI_TCP_KEEP_ALIVE", &transportConfig.KeepAlive)
+	if transportConfig.KeepAlive == 0 {
+		transportConfig.KeepAlive = -1 // 0 turns keep-alive probes off rather than using Go's default
+	}
+	transportConfig.DisableKeepAlives = os.Getenv("UNIFI_DISABLE_KEEP_ALIVES") == "true"
+
+	// Response cache for reads, invalidated by writes to the same resource
+	cacheEnabled := os.Getenv("UNIFI_RESPONSE_CACHE") != "false"
+	cacheTTLs := unifi.DefaultCacheTTLs()
+	if ttls := os.Getenv("UNIFI_CACHE_TTLS"); ttls != "" {
+		overrides, err := unifi.ParseCacheTTLs(ttls)
+		if err != nil {
+			logrus.WithError(err).Fatal("Invalid UNIFI_CACHE_TTLS")
+		}
+		for resource, ttl := range overrides {
+			cacheTTLs[resource] = ttl
+		}
+	}
+
+	// Controllers: a controllers file, or a single controller from the UNIFI_* variables
+	controllersConfig := &mcp.ControllersConfig{Default: mcp.DefaultControllerName}
+	if controllersFile := os.Getenv("UNIFI_CONTROLLERS_FILE"); controllersFile != "" {
+		controllersConfig, err = mcp.LoadControllersConfig(controllersFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load controllers")
+		}
+		logrus.WithFields(logrus.Fields{
+			"file":        controllersFile,
+			"controllers": len(controllersConfig.Controllers),
+		}).Info("Loaded controllers")
+	} else {
+		baseURL := os.Getenv("UNIFI_BASE_URL")
+		if baseURL == "" {
+			baseURL = "https://192.168.1.1"
+		}
+
+		// Authenticate with an API key, or with a session login on controllers without API keys
+		apiKey := os.Getenv("UNIFI_API_KEY")
+		username := os.Getenv("UNIFI_USERNAME")
+		password := os.Getenv("UNIFI_PASSWORD")
+		if (username == "") != (password == "") {
+			logrus.Fatal("UNIFI_USERNAME and UNIFI_PASSWORD must be set together")
+		}
+		if apiKey == "" && username == "" {
+			logrus.Fatal("UNIFI_API_KEY, or UNIFI_USERNAME and UNIFI_PASSWORD, must be set")
+		}
+
+		controllersConfig.Controllers = []mcp.ControllerConfig{{
+			Name:           mcp.DefaultControllerName,
+			BaseURL:        baseURL,
+			APIKey:         apiKey,
+			Username:       username,
+			Password:       password,
+			SkipSSLVerify:  os.Getenv("UNIFI_SKIP_SSL_VERIFY") == "true",
+			ControllerType: os.Getenv("UNIFI_CONTROLLER_TYPE"),
+			CAFile:         os.Getenv("UNIFI_CA_FILE"),
+			CertSHA256:     os.Getenv("UNIFI_CERT_SHA256"),
+			ClientCertFile: os.Getenv("UNIFI_CLIENT_CERT_FILE"),
+			ClientKeyFile:  os.Getenv("UNIFI_CLIENT_KEY_FILE"),
+		}}
+	}
+
+	controllers := make([]mcp.Controller, 0, len(controllersConfig.Controllers))
+	for _, c := range controllersConfig.Controllers {
+		// Controller type detection (UniFi OS console or standalone Network application)
+		controllerType, err := unifi.ParseControllerType(c.ControllerType)
+		if err != nil {
+			logrus.WithError(err).WithField("controller", c.Name).Fatal("Invalid controller type")
+		}
+		if c.SkipSSLVerify {
+			logrus.WithField("controller", c.Name).Warn("SSL verification disabled - prefer UNIFI_CA_FILE or UNIFI_CERT_SHA256 for self-signed certificates")
+		}
+		if c.Username != "" {
+			logrus.WithFields(logrus.Fields{"controller": c.Name, "username": c.Username}).Info("Using session login")
+		}
+
+		clientOpts := []unifi.ClientOption{
+			unifi.WithReadOnly(readOnly),
+			unifi.WithAuditRecorder(auditLog),
+			unifi.WithRetryPolicy(retryPolicy),
+			unifi.WithControllerType(controllerType),
+			unifi.WithAuthTTL(authTTL),
+			unifi.WithCredentials(c.Username, c.Password),
+		}
+		// Custom CA, certificate pinning and mTLS client certificate
+		tlsOptions := unifi.TLSOptions{
+			CAFile:         c.CAFile,
+			CertSHA256:     unifi.ParseFingerprints(c.CertSHA256),
+			ClientCertFile: c.ClientCertFile,
+			ClientKeyFile:  c.ClientKeyFile,
+		}
+		if tlsOptions.Enabled() {
+			tlsConfig, err := unifi.NewTLSConfig(tlsOptions)
+			if err != nil {
+				logrus.WithError(err).WithField("controller", c.Name).Fatal("Invalid TLS configuration")
+			}
+			clientOpts = append(clientOpts, unifi.WithTLSConfig(tlsConfig))
+		}
+		controllerTransport := transportConfig
+		controllerTransport.Proxy = defaultProxy
+		if c.ProxyURL != "" {
+			// LoadControllersConfig has validated the URL
+			controllerTransport.Proxy, _ = unifi.ParseProxyURL(c.ProxyURL)
+		}
+		if controllerTransport.Proxy != nil {
+			logrus.WithFields(logrus.Fields{"controller": c.Name, "proxy": controllerTransport.Proxy.Redacted()}).Info("Using outbound proxy")
+		}
+		clientOpts = append(clientOpts, unifi.WithTransportConfig(controllerTransport))
+		if cacheEnabled {
+			clientOpts = append(clientOpts, unifi.WithResponseCache(unifi.NewMemoryCache(), cacheTTLs))
+		}
+		client := unifi.NewNetworkClient(c.BaseURL, c.APIKey, c.SkipSSLVerify, clientOpts...)
+		controllers = append(controllers, mcp.Controller{Name: c.Name, Description: c.Description, Client: client})
+	}
+
+	// How long each controller's site list is cached for resolving site_id arguments
+	siteCacheTTL := mcp.DefaultSiteCacheTTL
+	if ttl := os.Getenv("MCP_SITE_CACHE_TTL"); ttl != "" {
+		siteCacheTTL, err = time.ParseDuration(ttl)
+		if err != nil || siteCacheTTL < 0 {
+			logrus.Fatal("MCP_SITE_CACHE_TTL must be a duration such as 5m")
+		}
+	}
 
 	// Determine transport mode
 	transport := strings.ToLower(os.Getenv("MCP_TRANSPORT"))
@@ -65,20 +247,112 @@ func main() {
 		transport = "stdio"
 	}
 
+	toolsAllow, err := mcp.ParsePatternList(os.Getenv("MCP_TOOLS_ALLOW"))
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid MCP_TOOLS_ALLOW")
+	}
+	toolsDeny, err := mcp.ParsePatternList(os.Getenv("MCP_TOOLS_DENY"))
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid MCP_TOOLS_DENY")
+	}
+
+	serverOpts := []mcp.ServerOption{
+		mcp.WithReadOnly(readOnly),
+		mcp.WithAuditLog(auditLog),
+		mcp.WithToolPatterns(toolsAllow, toolsDeny),
+		mcp.WithControllers(controllers, controllersConfig.Default),
+		mcp.WithSiteCacheTTL(siteCacheTTL),
+	}
+
+	// HTTP authentication (only applies to the http and sse transports)
+	tokens, err := mcp.ParseTokenList(os.Getenv("MCP_AUTH_TOKENS"))
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid MCP_AUTH_TOKENS")
+	}
+	authConfig := mcp.AuthConfig{
+		Tokens:      tokens,
+		JWKSFile:    os.Getenv("MCP_AUTH_JWKS_FILE"),
+		JWTIssuer:   os.Getenv("MCP_AUTH_JWT_ISSUER"),
+		JWTAudience: os.Getenv("MCP_AUTH_JWT_AUDIENCE"),
+	}
+	if tokenFile := os.Getenv("MCP_AUTH_TOKENS_FILE"); tokenFile != "" {
+		fileTokens, err := mcp.LoadTokenFile(tokenFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load MCP auth tokens")
+		}
+		if err := mcp.MergeTokens(authConfig.Tokens, fileTokens); err != nil {
+			logrus.WithError(err).Fatal("MCP_AUTH_TOKENS_FILE conflicts with MCP_AUTH_TOKENS")
+		}
+	}
+	if authConfig.Enabled() {
+		authenticator, err := mcp.NewAuthenticator(authConfig)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to configure MCP authentication")
+		}
+		serverOpts = append(serverOpts, mcp.WithAuthenticator(authenticator))
+	} else if transport != "stdio" {
+		logrus.Warn("MCP HTTP transport has no authentication configured - anyone who can reach it can use every tool")
+	}
+
+	// Role-based tool and site authorization for authenticated callers
+	if rolesFile := os.Getenv("MCP_ROLES_FILE"); rolesFile != "" {
+		rbacConfig, err := mcp.LoadRBACConfig(rolesFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load MCP roles")
+		}
+		authorizer, err := mcp.NewAuthorizer(*rbacConfig)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to configure MCP roles")
+		}
+		if !authConfig.Enabled() {
+			logrus.Warn("MCP_ROLES_FILE is set but no authentication is configured - roles only apply to authenticated callers")
+		}
+		serverOpts = append(serverOpts, mcp.WithAuthorizer(authorizer))
+	}
+
+	// Human approval workflow for selected write tools
+	if approvalTools := os.Getenv("MCP_APPROVAL_TOOLS"); approvalTools != "" {
+		patterns, err := mcp.ParsePatternList(approvalTools)
+		if err != nil {
+			logrus.WithError(err).Fatal("Invalid MCP_APPROVAL_TOOLS")
+		}
+		if transport == "stdio" || !authConfig.Enabled() {
+			logrus.Fatal("MCP_APPROVAL_TOOLS needs the http or sse transport with authentication configured, so changes are approved by someone other than their requester")
+		}
+		queueFile := os.Getenv("MCP_APPROVAL_FILE")
+		if queueFile == "" {
+			queueFile = "pending-changes.json"
+		}
+		queue, err := mcp.NewChangeQueue(queueFile)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load approval queue")
+		}
+		logrus.WithField("file", queueFile).Info("Approval workflow enabled")
+		serverOpts = append(serverOpts, mcp.WithApprovalQueue(queue, patterns))
+	}
+
+	// Initialize MCP server
+	server := mcp.NewServer(controllers[0].Client, serverOpts...)
+
+	httpAddr := os.Getenv("MCP_HTTP_ADDR")
+	if httpAddr == "" {
+		httpAddr = ":8000"
+	}
+
 	switch transport {
 	case "http":
-		httpAddr := os.Getenv("MCP_HTTP_ADDR")
-		if httpAddr == "" {
-			httpAddr = ":8000"
-		}
-		logrus.Infof("Starting UniFi Network MCP Server on HTTP at %s", httpAddr)
 		go func() {
 			if err := server.ServeHTTP(httpAddr, ctx); err != nil {
 				logrus.WithError(err).Fatal("HTTP Server error")
 			}
 		}()
+	case "sse":
+		go func() {
+			if err := server.ServeSSE(httpAddr, ctx); err != nil {
+				logrus.WithError(err).Fatal("SSE Server error")
+			}
+		}()
 	default:
-		logrus.Info("Starting UniFi Network MCP Server on stdio transport")
 		go func() {
 			if err := server.ServeStdio(ctx); err != nil {
 				logrus.WithError(err).Fatal("Server error")
@@ -92,3 +366,25 @@ func main() {
 	cancel()
 	logrus.Info("UniFi Network MCP Server stopped")
 }
+
+// durationFromEnv sets *value from the duration in environment variable name, if it is set
+func durationFromEnv(name string, value *time.Duration) {
+	if s := os.Getenv(name); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			logrus.Fatalf("%s must be a non-negative duration such as 30s", name)
+		}
+		*value = d
+	}
+}
+
+// intFromEnv sets *value from the number in environment variable name, if it is set
+func intFromEnv(name string, value *int) {
+	if s := os.Getenv(name); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			logrus.Fatalf("%s must be a non-negative number", name)
+		}
+		*value = n
+	}
+}
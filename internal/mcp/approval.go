@@ -0,0 +1,443 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+// Pending change states
+const (
+	ChangePending  = "pending"
+	ChangeApplied  = "applied"
+	ChangeFailed   = "failed"
+	ChangeRejected = "rejected"
+)
+
+// ErrChangeNotFound is returned for unknown change IDs
+var ErrChangeNotFound = errors.New("change not found")
+
+// PendingChange is a queued write tool call awaiting a human decision
+type PendingChange struct {
+	ID          string                 `json:"id"`
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments"`
+	RequestedBy *Identity              `json:"requested_by,omitempty"`
+	RequestedAt time.Time              `json:"requested_at"`
+	Status      string                 `json:"status"`
+	DecidedBy   string                 `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time             `json:"decided_at,omitempty"`
+	Reason      string                 `json:"reason,omitempty"`
+}
+
+// requester returns the subject that queued the change, or "" when unknown
+func (c *PendingChange) requester() string {
+	if c.RequestedBy == nil {
+		return ""
+	}
+	return c.RequestedBy.Subject
+}
+
+// ChangeQueue stores pending changes and persists them to a local JSON file
+type ChangeQueue struct {
+	mu      sync.Mutex
+	path    string
+	changes map[string]*PendingChange
+}
+
+// NewChangeQueue loads the queue stored at path, creating an empty one if the file does not exist
+func NewChangeQueue(path string) (*ChangeQueue, error) {
+	q := &ChangeQueue{
+		path:    path,
+		changes: make(map[string]*PendingChange),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read change queue: %w", err)
+	}
+
+	var changes []*PendingChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to parse change queue: %w", err)
+	}
+	for _, change := range changes {
+		q.changes[change.ID] = change
+	}
+	return q, nil
+}
+
+// Add queues a new change and returns it
+func (q *ChangeQueue) Add(tool string, arguments map[string]interface{}, requestedBy *Identity) (*PendingChange, error) {
+	id, err := newChangeID()
+	if err != nil {
+		return nil, err
+	}
+
+	change := &PendingChange{
+		ID:          id,
+		Tool:        tool,
+		Arguments:   arguments,
+		RequestedBy: requestedBy,
+		RequestedAt: time.Now().UTC(),
+		Status:      ChangePending,
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.changes[id] = change
+	if err := q.saveLocked(); err != nil {
+		delete(q.changes, id)
+		return nil, err
+	}
+	return change, nil
+}
+
+// List returns changes with the given status (all changes when status is empty), oldest first
+func (q *ChangeQueue) List(status string) []PendingChange {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	changes := make([]PendingChange, 0, len(q.changes))
+	for _, change := range q.changes {
+		if status == "" || change.Status == status {
+			changes = append(changes, *change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].RequestedAt.Before(changes[j].RequestedAt)
+	})
+	return changes
+}
+
+// Claim marks a pending change as decided by decidedBy so it cannot be decided twice,
+// and returns a copy of it. Unless allowRequester is set, the requester may not claim
+// their own change, and both identities must be known to tell them apart. The final
+// status is recorded with Resolve.
+func (q *ChangeQueue) Claim(id, decidedBy string, allowRequester bool) (PendingChange, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	change, ok := q.changes[id]
+	if !ok {
+		return PendingChange{}, ErrChangeNotFound
+	}
+	if change.Status != ChangePending || change.DecidedBy != "" {
+		return PendingChange{}, fmt.Errorf("change %s is already %s", id, change.Status)
+	}
+	if !allowRequester {
+		requester := change.requester()
+		if requester == "" || decidedBy == "" {
+			return PendingChange{}, fmt.Errorf("change %s cannot be approved without authenticated requester and approver identities", id)
+		}
+		if requester == decidedBy {
+			return PendingChange{}, fmt.Errorf("change %s was requested by %q and must be decided by someone else", id, requester)
+		}
+	}
+
+	change.DecidedBy = decidedBy
+	return *change, nil
+}
+
+// Resolve records the final status of a claimed change
+func (q *ChangeQueue) Resolve(id, status, reason string) (PendingChange, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	change, ok := q.changes[id]
+	if !ok {
+		return PendingChange{}, ErrChangeNotFound
+	}
+
+	now := time.Now().UTC()
+	change.Status = status
+	change.Reason = reason
+	change.DecidedAt = &now
+	return *change, q.saveLocked()
+}
+
+// saveLocked atomically writes the queue to disk; q.mu must be held
+func (q *ChangeQueue) saveLocked() error {
+	changes := make([]*PendingChange, 0, len(q.changes))
+	for _, change := range q.changes {
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].RequestedAt.Before(changes[j].RequestedAt)
+	})
+
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode change queue: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write change queue: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write change queue: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write change queue: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), q.path); err != nil {
+		return fmt.Errorf("failed to write change queue: %w", err)
+	}
+	return nil
+}
+
+func newChangeID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate change ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type approvedChangeKey struct{}
+
+// withApprovedChange marks ctx as executing an approved change so the approval gate lets it through
+func withApprovedChange(ctx context.Context, change PendingChange) context.Context {
+	return context.WithValue(ctx, approvedChangeKey{}, change)
+}
+
+// approvedChangeFromContext returns the approved change being executed in ctx, if any
+func approvedChangeFromContext(ctx context.Context) (PendingChange, bool) {
+	change, ok := ctx.Value(approvedChangeKey{}).(PendingChange)
+	return change, ok
+}
+
+// requiresApproval reports whether calls to the tool must be queued for approval
+func (s *Server) requiresApproval(tool mcp.Tool) bool {
+	return s.approvals != nil && isWriteTool(tool) && !isApprovalTool(tool.Name) && matchAny(s.approvalTools, tool.Name)
+}
+
+// supportsDryRun reports whether the tool takes a dry_run argument. Other tools ignore
+// it, so a dry_run flag passed to them must not let the call skip approval.
+func supportsDryRun(tool mcp.Tool) bool {
+	_, ok := tool.InputSchema.Properties["dry_run"]
+	return ok
+}
+
+func isApprovalTool(name string) bool {
+	return name == "approve_change" || name == "reject_change"
+}
+
+// requireApproval queues calls to tools that need approval instead of executing them
+func (s *Server) requireApproval(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool, ok := s.exposed[request.Params.Name]
+		if !ok || !s.requiresApproval(tool) || (supportsDryRun(tool) && request.GetBool("dry_run", false)) {
+			return next(ctx, request)
+		}
+		if _, approved := approvedChangeFromContext(ctx); approved {
+			return next(ctx, request)
+		}
+		// Reject malformed changes now rather than when they are approved
+		if err := validateToolPayload(request.Params.Name, request.GetArguments()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if _, err := s.selectController(ctx, request.GetArguments()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Without an identity no one could be shown to be another person than the requester
+		id, ok := IdentityFromContext(ctx)
+		if !ok || id.Subject == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("%s requires approval, which needs an authenticated caller", request.Params.Name)), nil
+		}
+		change, err := s.approvals.Add(request.Params.Name, request.GetArguments(), id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("Failed to queue change for approval", err), nil
+		}
+
+		s.callerLogger(ctx).WithFields(logrus.Fields{
+			"tool":      change.Tool,
+			"change_id": change.ID,
+		}).Info("Change queued for approval")
+
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"pending_approval": true,
+			"change_id":        change.ID,
+			"tool":             change.Tool,
+			"message":          "This change requires approval by another person. Use approve_change or reject_change with the change_id.",
+		})
+	}
+}
+
+func (s *Server) listPendingChanges(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: list_pending_changes")
+
+	status := request.GetString("status", ChangePending)
+	if status == "all" {
+		status = ""
+	}
+
+	// Changes carry their full arguments, so only list those of sites the caller may use
+	changes := []PendingChange{}
+	for _, change := range s.approvals.List(status) {
+		if s.canSeeChange(ctx, change) {
+			changes = append(changes, change)
+		}
+	}
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"changes": changes,
+		"count":   len(changes),
+	})
+}
+
+// canSeeChange reports whether the caller's roles permit the site a change targets.
+// The site is resolved on the change's controller; a change whose site cannot be
+// resolved is hidden from callers restricted to some sites.
+func (s *Server) canSeeChange(ctx context.Context, change PendingChange) bool {
+	if _, ok := IdentityFromContext(ctx); s.authz == nil || !ok {
+		return true
+	}
+	changeCtx, err := s.selectController(ctx, change.Arguments)
+	if err != nil {
+		return false
+	}
+	siteID, _ := change.Arguments["site_id"].(string)
+	_, err = s.findSite(changeCtx, siteID)
+	return err == nil
+}
+
+func (s *Server) approveChange(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: approve_change")
+
+	changeID := request.GetString("change_id", "")
+	if changeID == "" {
+		return mcp.NewToolResultError("change_id is required"), nil
+	}
+
+	approver := ""
+	if id, ok := IdentityFromContext(ctx); ok {
+		approver = id.Subject
+	}
+
+	change, err := s.approvals.Claim(changeID, approver, false)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to approve change", err), nil
+	}
+
+	handler, ok := s.toolHandlers[change.Tool]
+	if !ok {
+		change, _ = s.approvals.Resolve(changeID, ChangeFailed, "tool is no longer available")
+		return mcp.NewToolResultError(fmt.Sprintf("tool %s is no longer available", change.Tool)), nil
+	}
+
+	// Run the change as the requester, so their site permissions still apply
+	execCtx := withToolName(withApprovedChange(ctx, change), change.Tool)
+	if change.RequestedBy != nil {
+		execCtx = WithIdentity(execCtx, change.RequestedBy)
+	}
+	execCtx, err = s.selectController(execCtx, change.Arguments)
+	if err != nil {
+		change, _ = s.approvals.Resolve(changeID, ChangeFailed, err.Error())
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	toolRequest := mcp.CallToolRequest{}
+	toolRequest.Params.Name = change.Tool
+	toolRequest.Params.Arguments = change.Arguments
+
+	s.callerLogger(ctx).WithFields(logrus.Fields{
+		"tool":         change.Tool,
+		"change_id":    change.ID,
+		"requested_by": change.requester(),
+	}).Info("Applying approved change")
+
+	result, err := handler(execCtx, toolRequest)
+	status, reason := ChangeApplied, ""
+	if err != nil {
+		status, reason = ChangeFailed, err.Error()
+	} else if result.IsError {
+		status, reason = ChangeFailed, toolResultText(result)
+	}
+
+	change, saveErr := s.approvals.Resolve(changeID, status, reason)
+	if saveErr != nil {
+		s.logger.WithError(saveErr).Error("Failed to persist change decision")
+	}
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to apply change", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"change": change,
+		"result": toolResultValue(result),
+	})
+}
+
+func (s *Server) rejectChange(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: reject_change")
+
+	changeID := request.GetString("change_id", "")
+	if changeID == "" {
+		return mcp.NewToolResultError("change_id is required"), nil
+	}
+	reason := request.GetString("reason", "")
+
+	rejecter := ""
+	if id, ok := IdentityFromContext(ctx); ok {
+		rejecter = id.Subject
+	}
+
+	// Requesters may withdraw their own changes
+	if _, err := s.approvals.Claim(changeID, rejecter, true); err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to reject change", err), nil
+	}
+	change, err := s.approvals.Resolve(changeID, ChangeRejected, reason)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to reject change", err), nil
+	}
+
+	s.callerLogger(ctx).WithFields(logrus.Fields{
+		"tool":      change.Tool,
+		"change_id": change.ID,
+	}).Info("Change rejected")
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"success": true,
+		"change":  change,
+	})
+}
+
+// toolResultText returns the concatenated text content of a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	text := ""
+	for _, content := range result.Content {
+		if tc, ok := content.(mcp.TextContent); ok {
+			text += tc.Text
+		}
+	}
+	return text
+}
+
+// toolResultValue returns a tool result's text content, decoded when it is JSON
+func toolResultValue(result *mcp.CallToolResult) interface{} {
+	text := toolResultText(result)
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value
+	}
+	return text
+}
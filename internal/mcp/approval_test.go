@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+func TestChangeQueuePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.json")
+	queue, err := NewChangeQueue(path)
+	if err != nil {
+		t.Fatalf("NewChangeQueue failed: %v", err)
+	}
+
+	requester := &Identity{Subject: "agent", Method: AuthMethodToken}
+	change, err := queue.Add("patch_acl_rule", map[string]interface{}{"rule_id": "r1"}, requester)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	reloaded, err := NewChangeQueue(path)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	pending := reloaded.List(ChangePending)
+	if len(pending) != 1 || pending[0].ID != change.ID || pending[0].requester() != "agent" {
+		t.Fatalf("unexpected queue after reload: %+v", pending)
+	}
+
+	if _, err := reloaded.Claim(change.ID, "agent", false); err == nil {
+		t.Error("expected requester to be unable to approve their own change")
+	}
+	if _, err := reloaded.Claim(change.ID, "", false); err == nil {
+		t.Error("expected an anonymous caller to be unable to approve a change")
+	}
+	if _, err := reloaded.Claim(change.ID, "reviewer", false); err != nil {
+		t.Errorf("expected reviewer to claim change: %v", err)
+	}
+	if _, err := reloaded.Claim(change.ID, "someone-else", false); err == nil {
+		t.Error("expected a claimed change to be undecidable")
+	}
+
+	// Without a known requester no approver can be shown to be someone else
+	anonymous, err := reloaded.Add("patch_acl_rule", map[string]interface{}{"rule_id": "r2"}, nil)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := reloaded.Claim(anonymous.ID, "reviewer", false); err == nil {
+		t.Error("expected a change without requester to be unapprovable")
+	}
+	if _, err := reloaded.Claim(anonymous.ID, "", true); err != nil {
+		t.Errorf("expected a change without requester to be rejectable: %v", err)
+	}
+}
+
+func TestApprovalWorkflow(t *testing.T) {
+	queue, err := NewChangeQueue(filepath.Join(t.TempDir(), "changes.json"))
+	if err != nil {
+		t.Fatalf("NewChangeQueue failed: %v", err)
+	}
+	audit, err := NewAuditLog(AuditConfig{})
+	if err != nil {
+		t.Fatalf("NewAuditLog failed: %v", err)
+	}
+	s := newTestServer(WithApprovalQueue(queue, []string{"patch_acl_rule"}))
+
+	executed := 0
+	s.toolHandlers["patch_acl_rule"] = func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		executed++
+		if id, _ := IdentityFromContext(ctx); id == nil || id.Subject != "agent" {
+			t.Errorf("expected change to run as the requester, got %+v", id)
+		}
+		audit.RecordWrite(ctx, unifi.WriteRecord{Method: "PATCH", Resource: "rule", ObjectID: "r1"})
+		return mcp.NewToolResultJSON(map[string]interface{}{"success": true})
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "patch_acl_rule"
+	request.Params.Arguments = map[string]interface{}{"rule_id": "r1", "settings": map[string]interface{}{"enabled": false}}
+
+	// Anonymous callers cannot queue changes, as no one could approve them
+	if result, _ := s.requireApproval(s.toolHandlers["patch_acl_rule"])(context.Background(), request); !result.IsError {
+		t.Errorf("expected an anonymous change to be refused, got %v", result)
+	}
+
+	agentCtx := WithIdentity(context.Background(), &Identity{Subject: "agent", Method: AuthMethodToken})
+
+	result, err := s.requireApproval(s.toolHandlers["patch_acl_rule"])(agentCtx, request)
+	if err != nil || result.IsError {
+		t.Fatalf("expected change to be queued, got %v %v", result, err)
+	}
+	if executed != 0 {
+		t.Fatal("change executed before approval")
+	}
+
+	pending := queue.List(ChangePending)
+	if len(pending) != 1 {
+		t.Fatalf("expected one pending change, got %d", len(pending))
+	}
+
+	approve := mcp.CallToolRequest{}
+	approve.Params.Arguments = map[string]interface{}{"change_id": pending[0].ID}
+	reviewerCtx := WithIdentity(context.Background(), &Identity{Subject: "reviewer", Method: AuthMethodToken})
+
+	result, err = s.approveChange(reviewerCtx, approve)
+	if err != nil || result.IsError {
+		t.Fatalf("approve failed: %v %v", result, err)
+	}
+	if executed != 1 {
+		t.Errorf("expected change to execute once, executed %d times", executed)
+	}
+	if applied := queue.List(ChangeApplied); len(applied) != 1 || applied[0].DecidedBy != "reviewer" {
+		t.Errorf("expected change to be recorded as applied by reviewer, got %+v", applied)
+	}
+	if entries, _ := audit.Query(AuditQuery{}); len(entries) != 1 || entries[0].DecidedBy != "reviewer" || entries[0].ChangeID != pending[0].ID {
+		t.Errorf("expected the audit entry to name the change and its approver, got %+v", entries)
+	}
+}
+
+func TestApprovalGateOnlySkipsSupportedDryRuns(t *testing.T) {
+	queue, err := NewChangeQueue(filepath.Join(t.TempDir(), "changes.json"))
+	if err != nil {
+		t.Fatalf("NewChangeQueue failed: %v", err)
+	}
+	s := newTestServer(WithApprovalQueue(queue, []string{"patch_acl_rule", "block_client"}))
+	agentCtx := WithIdentity(context.Background(), &Identity{Subject: "agent", Method: AuthMethodToken})
+
+	executed := map[string]int{}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		executed[request.Params.Name]++
+		return mcp.NewToolResultJSON(map[string]interface{}{"success": true})
+	}
+
+	// patch_acl_rule plans its dry runs, so they run straight away
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "patch_acl_rule"
+	request.Params.Arguments = map[string]interface{}{"rule_id": "r1", "settings": map[string]interface{}{"enabled": false}, "dry_run": true}
+	if result, err := s.requireApproval(handler)(agentCtx, request); err != nil || result.IsError {
+		t.Fatalf("expected dry run to execute, got %v %v", result, err)
+	}
+
+	// block_client has no dry run and would act on the controller despite the flag
+	request = mcp.CallToolRequest{}
+	request.Params.Name = "block_client"
+	request.Params.Arguments = map[string]interface{}{"mac": "aa:bb:cc:dd:ee:ff", "dry_run": true}
+	if result, err := s.requireApproval(handler)(agentCtx, request); err != nil || result.IsError {
+		t.Fatalf("expected change to be queued, got %v %v", result, err)
+	}
+
+	if executed["patch_acl_rule"] != 1 || executed["block_client"] != 0 {
+		t.Errorf("expected only the supported dry run to execute, got %v", executed)
+	}
+	if pending := queue.List(ChangePending); len(pending) != 1 || pending[0].Tool != "block_client" {
+		t.Errorf("expected block_client to be queued, got %+v", pending)
+	}
+}
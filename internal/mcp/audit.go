@@ -0,0 +1,366 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// Audit entry result statuses
+const (
+	AuditSuccess = "success"
+	AuditError   = "error"
+	AuditRefused = "refused"
+)
+
+// auditMemoryEntries is how many entries are kept in memory when no audit file is configured
+const auditMemoryEntries = 1000
+
+// AuditEntry is one JSON line of the audit log
+type AuditEntry struct {
+	Time       time.Time              `json:"time"`
+	Caller     string                 `json:"caller,omitempty"`
+	AuthMethod string                 `json:"auth_method,omitempty"`
+	Tool       string                 `json:"tool,omitempty"`
+	Controller string                 `json:"controller,omitempty"`
+	ChangeID   string                 `json:"change_id,omitempty"`
+	DecidedBy  string                 `json:"decided_by,omitempty"`
+	Method     string                 `json:"method"`
+	URL        string                 `json:"url"`
+	SiteID     string                 `json:"site_id,omitempty"`
+	Resource   string                 `json:"resource,omitempty"`
+	ObjectID   string                 `json:"object_id,omitempty"`
+	Request    map[string]interface{} `json:"request,omitempty"`
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+	Status     string                 `json:"status"`
+	StatusCode int                    `json:"status_code,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+}
+
+// AuditConfig configures where the audit log is written
+type AuditConfig struct {
+	File     string // JSON lines file; entries are only kept in memory when empty
+	MaxBytes int64  // rotate the file once it grows beyond this size (0 disables rotation)
+	MaxFiles int    // number of rotated files to keep
+}
+
+// AuditQuery filters audit entries
+type AuditQuery struct {
+	Since  time.Time
+	Until  time.Time
+	Tool   string // glob pattern
+	Caller string
+	Limit  int
+	Filter func(AuditEntry) bool // keeps entries it returns true for, applied before Limit
+}
+
+// AuditLog is an append-only log of write requests sent to the controller
+type AuditLog struct {
+	mu     sync.Mutex
+	config AuditConfig
+	file   *os.File
+	size   int64
+	memory []AuditEntry
+	logger *logrus.Entry
+}
+
+// NewAuditLog opens the audit log described by config
+func NewAuditLog(config AuditConfig) (*AuditLog, error) {
+	a := &AuditLog{
+		config: config,
+		logger: logrus.WithField("component", "AuditLog"),
+	}
+	if config.File != "" {
+		if err := a.openFile(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (a *AuditLog) openFile() error {
+	f, err := os.OpenFile(a.config.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	a.file = f
+	a.size = info.Size()
+	return nil
+}
+
+// Close closes the audit log file
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// RecordWrite implements unifi.AuditRecorder
+func (a *AuditLog) RecordWrite(ctx context.Context, record unifi.WriteRecord) {
+	entry := AuditEntry{
+		Time:       time.Now().UTC(),
+		Tool:       toolNameFromContext(ctx),
+		Method:     record.Method,
+		URL:        record.URL,
+		SiteID:     record.SiteID,
+		Resource:   record.Resource,
+		ObjectID:   record.ObjectID,
+		Request:    record.Request,
+		Before:     record.Before,
+		After:      record.After,
+		Status:     AuditSuccess,
+		StatusCode: record.StatusCode,
+		DurationMS: record.Duration.Milliseconds(),
+	}
+	if id, ok := IdentityFromContext(ctx); ok {
+		entry.Caller = id.Subject
+		entry.AuthMethod = id.Method
+	}
+	if c, ok := controllerFromContext(ctx); ok {
+		entry.Controller = c.Name
+	}
+	if change, ok := approvedChangeFromContext(ctx); ok {
+		entry.ChangeID = change.ID
+		entry.DecidedBy = change.DecidedBy
+	}
+	if record.Err != nil {
+		entry.Status = AuditError
+		if errors.Is(record.Err, unifi.ErrReadOnly) {
+			entry.Status = AuditRefused
+		}
+		entry.Error = record.Err.Error()
+	}
+
+	if err := a.append(entry); err != nil {
+		a.logger.WithError(err).Error("Failed to write audit entry")
+	}
+
+	a.logger.WithFields(logrus.Fields{
+		"caller":    entry.Caller,
+		"tool":      entry.Tool,
+		"method":    entry.Method,
+		"site_id":   entry.SiteID,
+		"object_id": entry.ObjectID,
+		"status":    entry.Status,
+	}).Info("Audited write request")
+}
+
+func (a *AuditLog) append(entry AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		a.memory = append(a.memory, entry)
+		if len(a.memory) > auditMemoryEntries {
+			a.memory = a.memory[len(a.memory)-auditMemoryEntries:]
+		}
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if a.config.MaxBytes > 0 && a.size > 0 && a.size+int64(len(line)) > a.config.MaxBytes {
+		if err := a.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	return err
+}
+
+// rotateLocked shifts file -> file.1 -> file.2 ..., dropping the oldest; a.mu must be held
+func (a *AuditLog) rotateLocked() error {
+	if err := a.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+
+	maxFiles := a.config.MaxFiles
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	os.Remove(a.rotatedName(maxFiles))
+	for i := maxFiles - 1; i >= 1; i-- {
+		os.Rename(a.rotatedName(i), a.rotatedName(i+1))
+	}
+	if err := os.Rename(a.config.File, a.rotatedName(1)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return a.openFile()
+}
+
+func (a *AuditLog) rotatedName(n int) string {
+	return fmt.Sprintf("%s.%d", a.config.File, n)
+}
+
+// Query returns matching entries, newest first
+func (a *AuditLog) Query(q AuditQuery) ([]AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var entries []AuditEntry
+	if a.file == nil {
+		entries = append(entries, a.memory...)
+	} else {
+		// Oldest rotated file first, so entries stay in chronological order
+		for i := a.config.MaxFiles; i >= 1; i-- {
+			fileEntries, err := readAuditFile(a.rotatedName(i))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, fileEntries...)
+		}
+		fileEntries, err := readAuditFile(a.config.File)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	matched := []AuditEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && entry.Time.After(q.Until) {
+			continue
+		}
+		if q.Tool != "" && !matchAny([]string{q.Tool}, entry.Tool) {
+			continue
+		}
+		if q.Caller != "" && entry.Caller != q.Caller {
+			continue
+		}
+		if q.Filter != nil && !q.Filter(entry) {
+			continue
+		}
+		matched = append(matched, entry)
+		if q.Limit > 0 && len(matched) >= q.Limit {
+			break
+		}
+	}
+	return matched, nil
+}
+
+func readAuditFile(path string) ([]AuditEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+type toolNameKey struct{}
+
+// withToolName records the name of the tool handling ctx
+func withToolName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, toolNameKey{}, name)
+}
+
+// toolNameFromContext returns the tool name recorded in ctx, or ""
+func toolNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(toolNameKey{}).(string)
+	return name
+}
+
+func (s *Server) getAuditLog(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: get_audit_log")
+
+	query := AuditQuery{
+		Tool:   request.GetString("tool", ""),
+		Caller: request.GetString("caller", ""),
+		Limit:  request.GetInt("limit", 50),
+	}
+
+	var err error
+	if since := request.GetString("since", ""); since != "" {
+		if query.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return mcp.NewToolResultError("since must be an RFC3339 timestamp"), nil
+		}
+	}
+	if until := request.GetString("until", ""); until != "" {
+		if query.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return mcp.NewToolResultError("until must be an RFC3339 timestamp"), nil
+		}
+	}
+
+	// Entries carry request payloads, so only return those of sites the caller may use
+	query.Filter = func(entry AuditEntry) bool {
+		return s.canSeeAuditEntry(ctx, entry)
+	}
+
+	entries, err := s.audit.Query(query)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to read audit log", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// canSeeAuditEntry reports whether the caller's roles permit the site an entry was
+// written to, resolved on the entry's controller. Entries without a site, or whose
+// site no longer resolves, are only shown to callers permitted every site.
+func (s *Server) canSeeAuditEntry(ctx context.Context, entry AuditEntry) bool {
+	if _, ok := IdentityFromContext(ctx); s.authz == nil || !ok {
+		return true
+	}
+	entryCtx, err := s.selectController(ctx, map[string]interface{}{"controller": entry.Controller})
+	if err != nil {
+		return false
+	}
+	if entry.SiteID != "" {
+		if _, err := s.findSite(entryCtx, entry.SiteID); err == nil {
+			return true
+		}
+	}
+	return s.authorizeAllSites(entryCtx) == nil
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi/unifitest"
+)
+
+func TestAuditLogRecordsCallerAndTool(t *testing.T) {
+	audit, err := NewAuditLog(AuditConfig{})
+	if err != nil {
+		t.Fatalf("NewAuditLog failed: %v", err)
+	}
+
+	ctx := WithIdentity(context.Background(), &Identity{Subject: "ops", Method: AuthMethodToken})
+	audit.RecordWrite(withToolName(ctx, "patch_acl_rule"), unifi.WriteRecord{
+		Method:   "PATCH",
+		URL:      "https://controller/proxy/network/api/s/default/rest/rule/r1",
+		SiteID:   "default",
+		Resource: "rule",
+		ObjectID: "r1",
+		Request:  map[string]interface{}{"enabled": false},
+	})
+	audit.RecordWrite(withToolName(context.Background(), "create_acl_rule"), unifi.WriteRecord{
+		Method: "POST",
+		Err:    unifi.ErrReadOnly,
+	})
+
+	entries, err := audit.Query(AuditQuery{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Tool != "create_acl_rule" || entries[0].Status != AuditRefused {
+		t.Errorf("expected newest entry to be the refused create, got %+v", entries[0])
+	}
+	if entries[1].Caller != "ops" || entries[1].Tool != "patch_acl_rule" || entries[1].Status != AuditSuccess {
+		t.Errorf("unexpected patch entry: %+v", entries[1])
+	}
+
+	entries, _ = audit.Query(AuditQuery{Tool: "patch_*"})
+	if len(entries) != 1 || entries[0].ObjectID != "r1" {
+		t.Errorf("expected tool filter to match the patch only, got %+v", entries)
+	}
+	entries, _ = audit.Query(AuditQuery{Caller: "nobody"})
+	if len(entries) != 0 {
+		t.Errorf("expected no entries for unknown caller, got %d", len(entries))
+	}
+}
+
+func TestAuditLogHidesOtherSites(t *testing.T) {
+	fake := unifitest.NewFakeNetwork()
+	fake.Sites = []unifi.NetworkSite{
+		{ID: "s1", Name: "branch-oslo", ExternalID: "site-uuid-1"},
+		{ID: "s2", Name: "hq", ExternalID: "site-uuid-2"},
+	}
+	audit, err := NewAuditLog(AuditConfig{})
+	if err != nil {
+		t.Fatalf("NewAuditLog failed: %v", err)
+	}
+	for _, site := range []string{"branch-oslo", "hq", ""} {
+		audit.RecordWrite(context.Background(), unifi.WriteRecord{Method: "PATCH", SiteID: site, Resource: "wlanconf"})
+	}
+	s := NewServer(fake, WithAuthorizer(testAuthorizer(t)), WithAuditLog(audit))
+
+	for _, tc := range []struct {
+		caller string
+		want   int
+	}{
+		{"noc", 1},
+		{"helpdesk", 3},
+	} {
+		ctx := WithIdentity(context.Background(), &Identity{Subject: tc.caller, Method: AuthMethodToken})
+		result, err := s.getAuditLog(ctx, mcp.CallToolRequest{})
+		if err != nil || result.IsError {
+			t.Fatalf("get_audit_log failed: %v %v", result, err)
+		}
+		var listed struct {
+			Entries []AuditEntry `json:"entries"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &listed); err != nil {
+			t.Fatalf("failed to decode entries: %v", err)
+		}
+		if len(listed.Entries) != tc.want {
+			t.Errorf("expected %s to see %d entries, got %+v", tc.caller, tc.want, listed.Entries)
+		}
+		if tc.caller == "noc" && len(listed.Entries) == 1 && listed.Entries[0].SiteID != "branch-oslo" {
+			t.Errorf("expected noc to see the branch entry only, got %+v", listed.Entries)
+		}
+	}
+}
+
+func TestAuditLogRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(AuditConfig{File: path, MaxBytes: 300, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("NewAuditLog failed: %v", err)
+	}
+	defer audit.Close()
+
+	for i := 0; i < 10; i++ {
+		audit.RecordWrite(context.Background(), unifi.WriteRecord{
+			Method: "PATCH",
+			URL:    "https://controller/proxy/network/api/s/default/rest/networkconf/n1",
+			Err:    errors.New("boom"),
+		})
+	}
+
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Errorf("expected rotated file %s.2: %v", path, err)
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Errorf("expected at most 2 rotated files")
+	}
+
+	entries, err := audit.Query(AuditQuery{Limit: 3})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Status != AuditError {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
@@ -0,0 +1,253 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Authentication methods recorded on an Identity
+const (
+	AuthMethodToken = "token"
+	AuthMethodJWT   = "jwt"
+)
+
+// ErrUnauthenticated is returned when a request carries no usable credentials
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+// Identity describes the authenticated caller of an HTTP request
+type Identity struct {
+	Subject string                 `json:"subject"`          // token name or JWT "sub" claim
+	Method  string                 `json:"method"`           // AuthMethodToken or AuthMethodJWT
+	Claims  map[string]interface{} `json:"claims,omitempty"` // JWT claims, nil for static tokens
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the given identity
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the identity attached to ctx, if any
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok && id != nil
+}
+
+// AuthConfig configures HTTP transport authentication
+type AuthConfig struct {
+	Tokens      map[string]string // token name -> token value
+	JWKSFile    string            // path to a local JWKS file; enables JWT validation
+	JWTIssuer   string            // expected "iss" claim (optional)
+	JWTAudience string            // expected "aud" claim (optional)
+}
+
+// Enabled reports whether any authentication method is configured
+func (c AuthConfig) Enabled() bool {
+	return len(c.Tokens) > 0 || c.JWKSFile != ""
+}
+
+// staticToken is a named pre-shared bearer token or API key
+type staticToken struct {
+	name  string
+	value []byte
+}
+
+// Authenticator validates credentials presented to the HTTP transport
+type Authenticator struct {
+	tokens []staticToken
+	jwt    *jwtVerifier
+	logger *logrus.Entry
+}
+
+// NewAuthenticator creates an Authenticator from the given configuration
+func NewAuthenticator(cfg AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		logger: logrus.WithField("component", "Authenticator"),
+	}
+
+	for name, value := range cfg.Tokens {
+		if value == "" {
+			return nil, fmt.Errorf("token %q is empty", name)
+		}
+		a.tokens = append(a.tokens, staticToken{name: name, value: []byte(value)})
+	}
+
+	if cfg.JWKSFile != "" {
+		verifier, err := newJWTVerifier(cfg.JWKSFile, cfg.JWTIssuer, cfg.JWTAudience)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	return a, nil
+}
+
+// LoadTokenFile reads static tokens from a file. Each non-empty line is either
+// "identity=token" or a bare token; lines starting with # are ignored.
+func LoadTokenFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file: %w", err)
+	}
+	defer f.Close()
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := addToken(tokens, "file-token", line); err != nil {
+			return nil, fmt.Errorf("token file line %d: %w", number, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// ParseTokenList parses a comma-separated list of "identity=token" or bare token entries
+func ParseTokenList(list string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for i, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if err := addToken(tokens, "token", entry); err != nil {
+			return nil, fmt.Errorf("token list entry %d: %w", i+1, err)
+		}
+	}
+	return tokens, nil
+}
+
+// tokenIdentityPattern matches the identity of an "identity=token" entry
+var tokenIdentityPattern = regexp.MustCompile(`^[A-Za-z0-9._@-]+$`)
+
+// addToken adds one token entry. Only "=" names a token, and only the first one,
+// so tokens may contain ":" and "=" as long as they are given an identity.
+// Errors never quote the entry, as it may be a token.
+func addToken(tokens map[string]string, unnamedPrefix, entry string) error {
+	identity, token, named := strings.Cut(entry, "=")
+	if named {
+		identity, token = strings.TrimSpace(identity), strings.TrimSpace(token)
+		switch {
+		case !tokenIdentityPattern.MatchString(identity):
+			return errors.New(`expected "identity=token" with an identity of letters, digits, '.', '_', '@' or '-'`)
+		case token == "" || strings.HasPrefix(token, "="):
+			// A leading "=" is the padding of a bare base64 token cut in two
+			return errors.New(`expected "identity=token"; a token containing "=" must be given an identity`)
+		}
+	} else {
+		// Never derive the name from the token itself, it ends up in logs
+		identity, token = fmt.Sprintf("%s-%d", unnamedPrefix, len(tokens)+1), entry
+	}
+
+	// An explicit identity may take the name an unnamed token would get, or the other way round
+	if _, exists := tokens[identity]; exists {
+		return fmt.Errorf("identity %s is defined more than once", identity)
+	}
+	tokens[identity] = token
+	return nil
+}
+
+// MergeTokens adds the tokens of from to into, failing if an identity is in both
+func MergeTokens(into, from map[string]string) error {
+	for identity := range from {
+		if _, exists := into[identity]; exists {
+			return fmt.Errorf("identity %s is defined more than once", identity)
+		}
+	}
+	maps.Copy(into, from)
+	return nil
+}
+
+// Authenticate validates the credentials on r and returns the caller identity.
+// Credentials are read from "Authorization: Bearer <token>" or "X-API-Key".
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	credential := bearerToken(r)
+	if credential == "" {
+		credential = strings.TrimSpace(r.Header.Get("X-API-Key"))
+	}
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	if name, ok := a.matchStaticToken(credential); ok {
+		return &Identity{Subject: name, Method: AuthMethodToken}, nil
+	}
+
+	if a.jwt != nil && strings.Count(credential, ".") == 2 {
+		claims, err := a.jwt.Verify(credential)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+		subject, _ := claims["sub"].(string)
+		return &Identity{Subject: subject, Method: AuthMethodJWT, Claims: claims}, nil
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+// matchStaticToken compares credential against every static token in constant time
+func (a *Authenticator) matchStaticToken(credential string) (string, bool) {
+	matched := ""
+	for _, token := range a.tokens {
+		if subtle.ConstantTimeCompare(token.value, []byte(credential)) == 1 {
+			matched = token.name
+		}
+	}
+	return matched, matched != ""
+}
+
+// Middleware rejects unauthenticated requests and attaches the caller identity to the request context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := a.Authenticate(r)
+		if err != nil {
+			a.logger.WithFields(logrus.Fields{
+				"remote_addr": r.RemoteAddr,
+				"path":        r.URL.Path,
+			}).WithError(err).Warn("Rejected unauthenticated request")
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="unifi-network-mcp"`)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		a.logger.WithFields(logrus.Fields{
+			"subject": id.Subject,
+			"method":  id.Method,
+			"path":    r.URL.Path,
+		}).Debug("Authenticated request")
+
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+	})
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
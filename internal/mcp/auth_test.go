@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeJWKS(t *testing.T, key *ecdsa.PrivateKey, kid string) string {
+	t.Helper()
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"kid": kid,
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+		}},
+	}
+	data, _ := json.Marshal(jwks)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func TestAuthenticatorStaticTokens(t *testing.T) {
+	tokens, err := ParseTokenList("helpdesk=abc123, xyz789")
+	if err != nil {
+		t.Fatalf("ParseTokenList failed: %v", err)
+	}
+	auth, err := NewAuthenticator(AuthConfig{Tokens: tokens})
+	if err != nil {
+		t.Fatalf("NewAuthenticator failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		subject string
+		wantErr bool
+	}{
+		{"bearer named token", "Authorization", "Bearer abc123", "helpdesk", false},
+		{"api key header", "X-API-Key", "xyz789", "token-2", false},
+		{"wrong token", "Authorization", "Bearer nope", "", true},
+		{"no credentials", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			id, err := auth.Authenticate(req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected authentication to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id.Subject != tt.subject || id.Method != AuthMethodToken {
+				t.Errorf("unexpected identity %+v", id)
+			}
+		})
+	}
+}
+
+func TestParseTokenList(t *testing.T) {
+	tokens, err := ParseTokenList("helpdesk=ab:cd, ops = c2VjcmV0==, bare:token")
+	if err != nil {
+		t.Fatalf("ParseTokenList failed: %v", err)
+	}
+	want := map[string]string{"helpdesk": "ab:cd", "ops": "c2VjcmV0==", "token-3": "bare:token"}
+	if len(tokens) != len(want) {
+		t.Errorf("got %v, want %v", tokens, want)
+	}
+	for name, token := range want {
+		if tokens[name] != token {
+			t.Errorf("token %s: got %q, want %q", name, tokens[name], token)
+		}
+	}
+
+	for _, list := range []string{"c2VjcmV0==", "=abc", "help desk=abc", "ops=", "ops=a, ops=b", "token-2=a, b", "a, token-1=b"} {
+		if _, err := ParseTokenList(list); err == nil {
+			t.Errorf("ParseTokenList(%q) succeeded, want an error", list)
+		}
+	}
+}
+
+func TestMergeTokensRejectsDuplicateIdentities(t *testing.T) {
+	tokens := map[string]string{"helpdesk": "abc"}
+	if err := MergeTokens(tokens, map[string]string{"ops": "def"}); err != nil || tokens["ops"] != "def" {
+		t.Errorf("expected ops to be merged, got %v %v", tokens, err)
+	}
+	if err := MergeTokens(tokens, map[string]string{"helpdesk": "xyz"}); err == nil || tokens["helpdesk"] != "abc" {
+		t.Errorf("expected duplicate helpdesk to be rejected, got %v %v", tokens, err)
+	}
+}
+
+func TestLoadTokenFileRejectsInvalidLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte("# tokens\nhelpdesk=abc\n=xyz\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	if _, err := LoadTokenFile(path); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error for line 3, got %v", err)
+	}
+}
+
+func TestAuthenticatorJWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	auth, err := NewAuthenticator(AuthConfig{
+		JWKSFile:    writeJWKS(t, key, "k1"),
+		JWTIssuer:   "https://idp.example",
+		JWTAudience: "unifi-network-mcp",
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator failed: %v", err)
+	}
+
+	valid := map[string]interface{}{
+		"sub": "agent-7",
+		"iss": "https://idp.example",
+		"aud": []string{"unifi-network-mcp"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	expired := map[string]interface{}{
+		"sub": "agent-7",
+		"iss": "https://idp.example",
+		"aud": "unifi-network-mcp",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}
+	wrongAudience := map[string]interface{}{
+		"sub": "agent-7",
+		"iss": "https://idp.example",
+		"aud": "someone-else",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	noSubject := map[string]interface{}{
+		"iss": "https://idp.example",
+		"aud": "unifi-network-mcp",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer "+signES256(t, key, "k1", valid))
+	id, err := auth.Authenticate(req)
+	if err != nil {
+		t.Fatalf("expected valid token to authenticate: %v", err)
+	}
+	if id.Subject != "agent-7" || id.Method != AuthMethodJWT {
+		t.Errorf("unexpected identity %+v", id)
+	}
+
+	for name, claims := range map[string]map[string]interface{}{"expired": expired, "wrong audience": wrongAudience, "no subject": noSubject} {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.Header.Set("Authorization", "Bearer "+signES256(t, key, "k1", claims))
+		if _, err := auth.Authenticate(req); err == nil {
+			t.Errorf("%s: expected authentication to fail", name)
+		}
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	auth, err := NewAuthenticator(AuthConfig{Tokens: map[string]string{"ops": "secret"}})
+	if err != nil {
+		t.Fatalf("NewAuthenticator failed: %v", err)
+	}
+
+	var gotSubject string
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := IdentityFromContext(r.Context()); ok {
+			gotSubject = id.Subject
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without credentials, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || gotSubject != "ops" {
+		t.Errorf("expected authenticated request as ops, got status %d subject %q", rec.Code, gotSubject)
+	}
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// clientCommand sends a command to one client of the site with the given legacy name
+type clientCommand func(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+
+// macProperty is the input schema entry naming the client a client action acts on
+var macProperty = map[string]any{
+	"type":        "string",
+	"description": "Client MAC address in any common notation, e.g. AA:BB:CC:DD:EE:FF, aa-bb-cc-dd-ee-ff or aabb.ccdd.eeff (required)",
+}
+
+// runClientAction implements the tools that act on a client: it normalizes the
+// client's MAC address, sends the command and returns the acknowledgement
+func (s *Server) runClientAction(ctx context.Context, request mcp.CallToolRequest, action string, run clientCommand) (*mcp.CallToolResult, error) {
+	siteID := request.GetString("site_id", "")
+	mac, err := unifi.NormalizeMAC(request.GetString("mac", ""))
+	if err != nil {
+		return mcp.NewToolResultError("mac: " + err.Error()), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	ack, err := run(ctx, site.Name, mac)
+	if err != nil {
+		return toolResultError(fmt.Sprintf("Failed to %s client", strings.ReplaceAll(action, "_", " ")), err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"success":         true,
+		"action":          action,
+		"acknowledgement": ack,
+		"mac":             mac,
+		"site_id":         site.ExternalID,
+	})
+}
+
+func (s *Server) blockClient(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: block_client")
+	return s.runClientAction(ctx, request, "block", s.client(ctx).BlockClient)
+}
+
+func (s *Server) unblockClient(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: unblock_client")
+	return s.runClientAction(ctx, request, "unblock", s.client(ctx).UnblockClient)
+}
+
+func (s *Server) kickClient(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: kick_client")
+	return s.runClientAction(ctx, request, "kick", s.client(ctx).KickClient)
+}
+
+func (s *Server) forgetClient(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: forget_client")
+	if !request.GetBool("confirm", false) {
+		return mcp.NewToolResultError("confirm must be true to forget a client; its history, name and settings are deleted"), nil
+	}
+	return s.runClientAction(ctx, request, "forget", s.client(ctx).ForgetClient)
+}
+
+func (s *Server) authorizeGuest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: authorize_guest")
+
+	auth := unifi.GuestAuthorization{
+		Minutes:  request.GetInt("minutes", 0),
+		UpKbps:   request.GetInt("up_kbps", 0),
+		DownKbps: request.GetInt("down_kbps", 0),
+		MBytes:   request.GetInt("bytes_mb", 0),
+		APMAC:    request.GetString("ap_mac", ""),
+	}
+	if auth.Minutes < 1 {
+		return mcp.NewToolResultError("minutes is required and must be at least 1"), nil
+	}
+	if auth.UpKbps < 0 || auth.DownKbps < 0 || auth.MBytes < 0 {
+		return mcp.NewToolResultError("up_kbps, down_kbps and bytes_mb must not be negative"), nil
+	}
+	if auth.APMAC != "" {
+		apMAC, err := unifi.NormalizeMAC(auth.APMAC)
+		if err != nil {
+			return mcp.NewToolResultError("ap_mac: " + err.Error()), nil
+		}
+		auth.APMAC = apMAC
+	}
+
+	return s.runClientAction(ctx, request, "authorize_guest", func(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+		return s.client(ctx).AuthorizeGuest(ctx, siteID, mac, auth)
+	})
+}
+
+func (s *Server) unauthorizeGuest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: unauthorize_guest")
+	return s.runClientAction(ctx, request, "unauthorize_guest", s.client(ctx).UnauthorizeGuest)
+}
@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// DefaultControllerName names the controller configured through UNIFI_BASE_URL when no controllers file is used
+const DefaultControllerName = "default"
+
+// ControllerConfig describes one controller in the controllers file. String
+// values may reference environment variables as $NAME or ${NAME}, so secrets
+// can stay out of the file.
+type ControllerConfig struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	BaseURL        string `json:"base_url"`
+	APIKey         string `json:"api_key"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	SkipSSLVerify  bool   `json:"skip_ssl_verify"`
+	ControllerType string `json:"controller_type"` // auto (default), unifi-os or classic
+	CAFile         string `json:"ca_file"`
+	CertSHA256     string `json:"cert_sha256"` // comma-separated fingerprints of the controller certificate
+	ClientCertFile string `json:"client_cert_file"`
+	ClientKeyFile  string `json:"client_key_file"`
+	ProxyURL       string `json:"proxy_url"` // outbound proxy for this controller (default: from the environment)
+}
+
+// ControllersConfig lists the controllers one server spans
+type ControllersConfig struct {
+	Default     string             `json:"default"` // controller used when a call names none (default: the first)
+	Controllers []ControllerConfig `json:"controllers"`
+}
+
+// LoadControllersConfig reads and validates a controllers file
+func LoadControllersConfig(path string) (*ControllersConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read controllers file: %w", err)
+	}
+
+	var cfg ControllersConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse controllers file: %w", err)
+	}
+
+	if len(cfg.Controllers) == 0 {
+		return nil, fmt.Errorf("no controllers defined")
+	}
+	seen := make(map[string]bool)
+	for i := range cfg.Controllers {
+		c := &cfg.Controllers[i]
+		for _, field := range []*string{&c.BaseURL, &c.APIKey, &c.Username, &c.Password, &c.CAFile, &c.ClientCertFile, &c.ClientKeyFile, &c.ProxyURL} {
+			*field = os.ExpandEnv(*field)
+		}
+
+		switch {
+		case c.Name == "":
+			return nil, fmt.Errorf("controller %d has no name", i+1)
+		case seen[c.Name]:
+			return nil, fmt.Errorf("controller %q is defined twice", c.Name)
+		case c.BaseURL == "":
+			return nil, fmt.Errorf("controller %q has no base_url", c.Name)
+		case c.APIKey == "" && (c.Username == "" || c.Password == ""):
+			return nil, fmt.Errorf("controller %q needs an api_key or a username and password", c.Name)
+		}
+		if _, err := unifi.ParseControllerType(c.ControllerType); err != nil {
+			return nil, fmt.Errorf("controller %q: %w", c.Name, err)
+		}
+		if _, err := unifi.ParseProxyURL(c.ProxyURL); err != nil {
+			return nil, fmt.Errorf("controller %q: %w", c.Name, err)
+		}
+		seen[c.Name] = true
+	}
+
+	if cfg.Default == "" {
+		cfg.Default = cfg.Controllers[0].Name
+	} else if !seen[cfg.Default] {
+		return nil, fmt.Errorf("default controller %q is not defined", cfg.Default)
+	}
+	return &cfg, nil
+}
+
+// Controller is a named controller the server can send tool calls to
+type Controller struct {
+	Name        string
+	Description string
+	Client      unifi.NetworkAPI
+}
+
+// WithControllers lets tool calls pick one of controllers with their controller
+// argument; calls without one go to the controller named defaultName
+func WithControllers(controllers []Controller, defaultName string) ServerOption {
+	return func(s *Server) {
+		s.controllers = make(map[string]*Controller, len(controllers))
+		for i := range controllers {
+			s.controllers[controllers[i].Name] = &controllers[i]
+		}
+		if c, ok := s.controllers[defaultName]; ok {
+			s.defaultCtrl = defaultName
+			s.networkClient = c.Client
+		}
+	}
+}
+
+// controllerProperty is added to the input schema of every tool that talks to a controller
+var controllerProperty = map[string]any{
+	"type":        "string",
+	"description": "Controller name from list_controllers (optional, defaults to the default controller)",
+}
+
+// localTools do not talk to a controller, so they take no controller argument
+var localTools = map[string]bool{
+	"list_controllers":     true,
+	"list_pending_changes": true,
+	"approve_change":       true,
+	"reject_change":        true,
+	"get_audit_log":        true,
+}
+
+type controllerKey struct{}
+
+// selectController returns ctx carrying the controller named by the call's
+// controller argument, or the default controller when there is none
+func (s *Server) selectController(ctx context.Context, arguments map[string]interface{}) (context.Context, error) {
+	name, _ := arguments["controller"].(string)
+	if name == "" {
+		name = s.defaultCtrl
+	}
+	if name == "" {
+		return ctx, nil
+	}
+
+	c, ok := s.controllers[name]
+	if !ok {
+		return ctx, fmt.Errorf("unknown controller %q (known controllers: %s)", name, strings.Join(s.controllerNames(), ", "))
+	}
+	return context.WithValue(ctx, controllerKey{}, c), nil
+}
+
+// controllerFromContext returns the controller selected for the call in ctx
+func controllerFromContext(ctx context.Context) (*Controller, bool) {
+	c, ok := ctx.Value(controllerKey{}).(*Controller)
+	return c, ok
+}
+
+// client returns the API client of the controller selected for the call in ctx
+func (s *Server) client(ctx context.Context) unifi.NetworkAPI {
+	if c, ok := controllerFromContext(ctx); ok {
+		return c.Client
+	}
+	return s.networkClient
+}
+
+func (s *Server) controllerNames() []string {
+	names := make([]string, 0, len(s.controllers))
+	for name := range s.controllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Server) listControllers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: list_controllers")
+
+	controllers := []map[string]interface{}{}
+	for _, name := range s.controllerNames() {
+		c := s.controllers[name]
+		controllers = append(controllers, map[string]interface{}{
+			"name":            c.Name,
+			"description":     c.Description,
+			"base_url":        c.Client.BaseURL(),
+			"controller_type": c.Client.ControllerType(),
+			"read_only":       c.Client.ReadOnly(),
+			"default":         c.Name == s.defaultCtrl,
+		})
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"controllers": controllers,
+		"count":       len(controllers),
+	})
+}
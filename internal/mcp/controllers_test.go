@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+func TestLoadControllersConfig(t *testing.T) {
+	t.Setenv("BRANCH_KEY", "secret-key")
+	path := filepath.Join(t.TempDir(), "controllers.json")
+	os.WriteFile(path, []byte(`{
+		"default": "branch",
+		"controllers": [
+			{"name": "hq", "base_url": "https://hq.example.com", "username": "admin", "password": "pw"},
+			{"name": "branch", "base_url": "https://branch.example.com:8443", "api_key": "${BRANCH_KEY}", "controller_type": "classic"}
+		]
+	}`), 0o600)
+
+	cfg, err := LoadControllersConfig(path)
+	if err != nil {
+		t.Fatalf("LoadControllersConfig failed: %v", err)
+	}
+	if cfg.Default != "branch" || len(cfg.Controllers) != 2 || cfg.Controllers[1].APIKey != "secret-key" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	os.WriteFile(path, []byte(`{"controllers": [{"name": "hq", "base_url": "https://hq.example.com"}]}`), 0o600)
+	if _, err := LoadControllersConfig(path); err == nil {
+		t.Error("expected a controller without credentials to be rejected")
+	}
+}
+
+func TestToolCallsRouteToController(t *testing.T) {
+	newController := func(siteName string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/integration/v1/info"):
+				json.NewEncoder(w).Encode(map[string]interface{}{"applicationVersion": "9.0.114"})
+			case strings.HasSuffix(r.URL.Path, "/api/self/sites"):
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": []map[string]interface{}{{"_id": "s1", "name": siteName}},
+				})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+	hq, branch := newController("hq-site"), newController("branch-site")
+	defer hq.Close()
+	defer branch.Close()
+
+	controllers := []Controller{
+		{Name: "hq", Client: unifi.NewNetworkClient(hq.URL, "key", false)},
+		{Name: "branch", Client: unifi.NewNetworkClient(branch.URL, "key", false)},
+	}
+	s := NewServer(controllers[0].Client, WithControllers(controllers, "hq"))
+
+	if _, ok := s.server.ListTools()["get_network_sites"].Tool.InputSchema.Properties["controller"]; !ok {
+		t.Error("expected get_network_sites to take a controller argument")
+	}
+
+	call := func(arguments map[string]interface{}) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "get_network_sites"
+		request.Params.Arguments = arguments
+		result, err := s.logToolCall(s.getNetworkSites)(context.Background(), request)
+		if err != nil {
+			t.Fatalf("tool call failed: %v", err)
+		}
+		return result
+	}
+
+	if text := toolResultText(call(map[string]interface{}{})); !strings.Contains(text, "hq-site") {
+		t.Errorf("expected the default controller, got %s", text)
+	}
+	if text := toolResultText(call(map[string]interface{}{"controller": "branch"})); !strings.Contains(text, "branch-site") {
+		t.Errorf("expected the branch controller, got %s", text)
+	}
+	if result := call(map[string]interface{}{"controller": "nowhere"}); !result.IsError {
+		t.Error("expected an unknown controller to be rejected")
+	}
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// deviceCommand sends a command to one device of a site
+type deviceCommand func(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+
+// deviceCommandPlan returns the request a deviceCommand would send
+type deviceCommandPlan func(siteID, mac string) unifi.RequestPlan
+
+// runDeviceAction implements the tools that act on an adopted device: it looks
+// the device up by ID for its MAC address, sends the command and returns the
+// acknowledgement together with the device as the controller reports it afterwards.
+// Actions with a plan support dry_run, which returns the command without sending it.
+func (s *Server) runDeviceAction(ctx context.Context, request mcp.CallToolRequest, action string, run deviceCommand,
+	plan deviceCommandPlan) (*mcp.CallToolResult, error) {
+	siteID := request.GetString("site_id", "")
+	deviceID := request.GetString("device_id", "")
+	if deviceID == "" {
+		return mcp.NewToolResultError("device_id is required"), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	device, err := s.client(ctx).GetDeviceDetailed(ctx, site, deviceID)
+	if err != nil {
+		return toolResultError("Failed to get device", err), nil
+	}
+	if device.MAC == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("device %s has no MAC address to address the command to", deviceID)), nil
+	}
+
+	if plan != nil && request.GetBool("dry_run", false) {
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"dry_run":   true,
+			"request":   plan(site.Name, device.MAC),
+			"device":    device.Summary(),
+			"device_id": deviceID,
+			"site_id":   site.ExternalID,
+		})
+	}
+
+	ack, err := run(ctx, site.Name, device.MAC)
+	if err != nil {
+		return toolResultError(fmt.Sprintf("Failed to %s device", strings.ReplaceAll(action, "_", " ")), err), nil
+	}
+
+	result := map[string]interface{}{
+		"success":         true,
+		"action":          action,
+		"acknowledgement": ack,
+		"device_id":       deviceID,
+		"mac":             device.MAC,
+		"site_id":         site.ExternalID,
+	}
+	if action != "forget" {
+		// The write invalidated cached device reads, so this is the controller's current view
+		if after, err := s.client(ctx).GetDeviceDetailed(ctx, site, deviceID); err == nil {
+			result["device"] = after.Summary()
+		} else {
+			s.logger.WithError(err).Debug("Failed to fetch device state after command")
+		}
+	}
+	return mcp.NewToolResultJSON(result)
+}
+
+func (s *Server) restartDevice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: restart_device")
+	return s.runDeviceAction(ctx, request, "restart", s.client(ctx).RestartDevice, s.client(ctx).PlanRestartDevice)
+}
+
+func (s *Server) locateDevice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: locate_device")
+	enabled := request.GetBool("enabled", true)
+	action := "locate"
+	if !enabled {
+		action = "unlocate"
+	}
+	return s.runDeviceAction(ctx, request, action, func(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+		return s.client(ctx).LocateDevice(ctx, siteID, mac, enabled)
+	}, nil)
+}
+
+func (s *Server) forceProvisionDevice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: force_provision_device")
+	return s.runDeviceAction(ctx, request, "force_provision", s.client(ctx).ForceProvisionDevice, nil)
+}
+
+func (s *Server) forgetDevice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: forget_device")
+	if !request.GetBool("dry_run", false) && !request.GetBool("confirm", false) {
+		return mcp.NewToolResultError("confirm must be true to forget a device; it has to be adopted again afterwards. Use dry_run to preview the command first"), nil
+	}
+	return s.runDeviceAction(ctx, request, "forget", s.client(ctx).ForgetDevice, s.client(ctx).PlanForgetDevice)
+}
+
+// adoptDevice adopts a pending device. Pending devices have no ID yet, so it is
+// addressed by MAC address and looked up among the site's devices afterwards.
+func (s *Server) adoptDevice(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: adopt_device")
+
+	siteID := request.GetString("site_id", "")
+	mac := strings.ToLower(request.GetString("mac", ""))
+	if mac == "" {
+		return mcp.NewToolResultError("mac is required"), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	ack, err := s.client(ctx).AdoptDevice(ctx, site.Name, mac)
+	if err != nil {
+		return toolResultError("Failed to adopt device", err), nil
+	}
+
+	result := map[string]interface{}{
+		"success":         true,
+		"action":          "adopt",
+		"acknowledgement": ack,
+		"mac":             mac,
+		"site_id":         site.ExternalID,
+	}
+	if devices, err := s.client(ctx).GetDevices(ctx, site.ExternalID); err == nil {
+		for i := range devices {
+			if strings.EqualFold(devices[i].MAC, mac) {
+				result["device"] = devices[i]
+				result["device_id"] = devices[i].ID
+				break
+			}
+		}
+	} else {
+		s.logger.WithError(err).Debug("Failed to fetch devices after adoption")
+	}
+	return mcp.NewToolResultJSON(result)
+}
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// Kinds of field change reported by a dry run
+const (
+	changeAdded     = "added"
+	changeModified  = "modified"
+	changeRemoved   = "removed"
+	changeUnchanged = "unchanged"
+)
+
+// dryRunProperty is the input schema entry shared by every tool that supports dry runs
+var dryRunProperty = map[string]any{
+	"type":        "boolean",
+	"description": "Preview the change without applying it: returns the field-level diff and the exact request (optional, default false)",
+}
+
+// fieldChange is a single field-level difference between the current and the requested object
+type fieldChange struct {
+	Field  string      `json:"field"`
+	Change string      `json:"change"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// diffFields compares the fields set in requested against current. Nested objects
+// are compared field by field and reported with dotted paths; fields of current
+// that requested does not mention are left out, as a PATCH does not touch them,
+// and fields missing from current are reported as added, with no before value.
+func diffFields(current, requested map[string]interface{}) []fieldChange {
+	changes := []fieldChange{}
+	collectChanges("", current, requested, &changes)
+	return changes
+}
+
+func collectChanges(prefix string, current, requested map[string]interface{}, changes *[]fieldChange) {
+	keys := make([]string, 0, len(requested))
+	for key := range requested {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+
+		after := requested[key]
+		before, exists := current[key]
+
+		beforeMap, beforeIsMap := before.(map[string]interface{})
+		afterMap, afterIsMap := after.(map[string]interface{})
+		switch {
+		case !exists:
+			*changes = append(*changes, fieldChange{Field: field, Change: changeAdded, After: after})
+		case beforeIsMap && afterIsMap:
+			collectChanges(field, beforeMap, afterMap, changes)
+			// A nested object is sent whole, so fields it no longer carries are removed
+			for _, removed := range sortedMissingKeys(beforeMap, afterMap) {
+				*changes = append(*changes, fieldChange{Field: field + "." + removed, Change: changeRemoved, Before: beforeMap[removed]})
+			}
+		case jsonEqual(before, after):
+			*changes = append(*changes, fieldChange{Field: field, Change: changeUnchanged, Before: before, After: after})
+		default:
+			*changes = append(*changes, fieldChange{Field: field, Change: changeModified, Before: before, After: after})
+		}
+	}
+}
+
+func sortedMissingKeys(from, in map[string]interface{}) []string {
+	var missing []string
+	for key := range from {
+		if _, ok := in[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// jsonEqual compares two decoded JSON values, treating all numbers as float64
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeJSON(a), normalizeJSON(b))
+}
+
+func normalizeJSON(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case []interface{}:
+		out := make([]interface{}, len(n))
+		for i, entry := range n {
+			out[i] = normalizeJSON(entry)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(n))
+		for key, entry := range n {
+			out[key] = normalizeJSON(entry)
+		}
+		return out
+	}
+	return v
+}
+
+// countChanges returns the number of fields a plan would actually change
+func countChanges(changes []fieldChange) int {
+	count := 0
+	for _, change := range changes {
+		if change.Change != changeUnchanged {
+			count++
+		}
+	}
+	return count
+}
+
+// planPatch fetches the current object and returns what a PATCH with settings would change, without sending it.
+// The object is fetched as the controller returned it, so fields it does not carry are reported as added.
+func (s *Server) planPatch(ctx context.Context, site unifi.NetworkSite, resource unifi.Resource, id string, settings map[string]interface{}) (*mcp.CallToolResult, error) {
+	current, err := s.client(ctx).GetObject(ctx, site.Name, resource, id)
+	if err != nil {
+		return toolResultError("Failed to fetch current object for dry run", err), nil
+	}
+	return s.planPatchFrom(ctx, site, resource, id, settings, current)
+}
+
+// planPatchFrom returns what a PATCH with settings would change in current, without sending it
+func (s *Server) planPatchFrom(ctx context.Context, site unifi.NetworkSite, resource unifi.Resource, id string, settings,
+	current map[string]interface{}) (*mcp.CallToolResult, error) {
+	changes := diffFields(current, settings)
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"dry_run":       true,
+		"request":       s.client(ctx).PlanPatch(site.Name, resource, id, settings),
+		"changes":       changes,
+		"changed_count": countChanges(changes),
+		"current":       current,
+		"site_id":       site.ExternalID,
+	})
+}
+
+// planCreate returns the request that would create an object from config, without sending it
+func (s *Server) planCreate(ctx context.Context, site unifi.NetworkSite, resource unifi.Resource, config map[string]interface{}) (*mcp.CallToolResult, error) {
+	changes := diffFields(map[string]interface{}{}, config)
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"dry_run":       true,
+		"request":       s.client(ctx).PlanCreate(site.Name, resource, config),
+		"changes":       changes,
+		"changed_count": countChanges(changes),
+		"site_id":       site.ExternalID,
+	})
+}
+
+// planDelete fetches the object and returns the request that would delete it, without sending it
+func (s *Server) planDelete(ctx context.Context, site unifi.NetworkSite, resource unifi.Resource, id string) (*mcp.CallToolResult, error) {
+	current, err := s.client(ctx).GetObject(ctx, site.Name, resource, id)
+	if err != nil {
+		return toolResultError("Failed to fetch current object for dry run", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"dry_run": true,
+		"request": s.client(ctx).PlanDelete(site.Name, resource, id),
+		"current": current,
+		"site_id": site.ExternalID,
+	})
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi/unifitest"
+)
+
+func TestDiffFields(t *testing.T) {
+	current := map[string]interface{}{
+		"name":    "Guest",
+		"enabled": true,
+		"vlan":    float64(10),
+		"radius":  map[string]interface{}{"server": "10.0.0.5", "port": float64(1812)},
+	}
+	requested := map[string]interface{}{
+		"name":     "Guest",
+		"enabled":  false,
+		"vlan":     10,
+		"security": "wpa2",
+		"radius":   map[string]interface{}{"server": "10.0.0.6"},
+	}
+
+	got := map[string]string{}
+	for _, change := range diffFields(current, requested) {
+		got[change.Field] = change.Change
+	}
+
+	want := map[string]string{
+		"name":          changeUnchanged,
+		"enabled":       changeModified,
+		"vlan":          changeUnchanged,
+		"security":      changeAdded,
+		"radius.server": changeModified,
+		"radius.port":   changeRemoved,
+	}
+	for field, change := range want {
+		if got[field] != change {
+			t.Errorf("field %s: got %q, want %q", field, got[field], change)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("unexpected changes: %v", got)
+	}
+}
+
+func TestPatchDryRunSendsNothing(t *testing.T) {
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != http.MethodGet:
+			t.Errorf("dry run sent %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/integration/v1/info"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"applicationVersion": "9.0.114"})
+		case strings.HasSuffix(r.URL.Path, "/api/self/sites"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"_id": "s1", "name": "default", "external_id": "site-uuid"}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/rest/networkconf/net1"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"_id": "net1", "name": "Guest", "enabled": true},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer controller.Close()
+
+	s := NewServer(unifi.NewNetworkClient(controller.URL, "test-api-key", false))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "patch_wifi_network"
+	request.Params.Arguments = map[string]interface{}{
+		"network_id": "net1",
+		"settings":   map[string]interface{}{"enabled": false},
+		"dry_run":    true,
+	}
+
+	result, err := s.patchWiFiNetwork(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("dry run failed: %v", result.Content)
+	}
+
+	var plan struct {
+		DryRun  bool              `json:"dry_run"`
+		Request unifi.RequestPlan `json:"request"`
+		Count   int               `json:"changed_count"`
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if err := json.Unmarshal([]byte(text), &plan); err != nil {
+		t.Fatalf("failed to decode plan: %v", err)
+	}
+	if !plan.DryRun || plan.Request.Method != "PATCH" || plan.Count != 1 {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+	if !strings.HasSuffix(plan.Request.URL, "/api/s/default/rest/networkconf/net1") {
+		t.Errorf("unexpected plan URL %s", plan.Request.URL)
+	}
+}
+
+func TestPatchDryRunReportsMissingFieldsAsAdded(t *testing.T) {
+	fake := unifitest.NewFakeNetwork()
+	fake.Objects = map[unifi.Resource][]map[string]interface{}{
+		unifi.ResourceWiFiNetwork: {{"_id": "net1", "name": "Guest"}},
+	}
+	s := NewServer(fake)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "patch_wifi_network"
+	request.Params.Arguments = map[string]interface{}{
+		"network_id": "net1",
+		"settings":   map[string]interface{}{"name": "Guest", "purpose": "guest", "enabled": false},
+		"dry_run":    true,
+	}
+
+	result, err := s.patchWiFiNetwork(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("dry run failed: %v", result.Content)
+	}
+
+	var plan struct {
+		Changes []map[string]interface{} `json:"changes"`
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if err := json.Unmarshal([]byte(text), &plan); err != nil {
+		t.Fatalf("failed to decode plan: %v", err)
+	}
+
+	want := map[string]string{"name": changeUnchanged, "purpose": changeAdded, "enabled": changeAdded}
+	for _, change := range plan.Changes {
+		field := change["field"].(string)
+		if change["change"] != want[field] {
+			t.Errorf("field %s: got %v, want %q", field, change["change"], want[field])
+		}
+		if _, hasBefore := change["before"]; hasBefore && change["change"] == changeAdded {
+			t.Errorf("added field %s reports a before value %v", field, change["before"])
+		}
+	}
+	if len(plan.Changes) != len(want) {
+		t.Errorf("unexpected changes: %v", plan.Changes)
+	}
+	if calls := fake.CallsTo("GetObject"); len(calls) != 1 || calls[0].SiteID != "default" {
+		t.Errorf("unexpected fetches: %+v", calls)
+	}
+}
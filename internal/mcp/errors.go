@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// Error kinds reported in the structured content of failed tool calls
+const (
+	ErrorKindNotFound     = "not_found"
+	ErrorKindSiteNotFound = "site_not_found"
+	ErrorKindUnauthorized = "unauthorized"
+	ErrorKindValidation   = "validation"
+	ErrorKindRateLimited  = "rate_limited"
+	ErrorKindController   = "controller_error"
+)
+
+// toolResultError is mcp.NewToolResultErrorFromErr for controller errors: an
+// *unifi.APIError additionally gets a hint on what to do next and its details
+// in _meta, so agents can branch on the kind of failure. Structured content is
+// left unset, as it would have to match the tool's output schema for successes.
+func toolResultError(text string, err error) *mcp.CallToolResult {
+	result := mcp.NewToolResultErrorFromErr(text, err)
+
+	var apiErr *unifi.APIError
+	if !errors.As(err, &apiErr) {
+		return result
+	}
+
+	kind, hint := classifyAPIError(err)
+	if hint != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(hint))
+	}
+
+	details := map[string]interface{}{
+		"kind":        kind,
+		"status_code": apiErr.StatusCode,
+		"method":      apiErr.Method,
+		"path":        apiErr.Path,
+	}
+	if apiErr.Code != "" {
+		details["code"] = apiErr.Code
+	}
+	if apiErr.Message != "" {
+		details["message"] = apiErr.Message
+	}
+	if apiErr.RequestID != "" {
+		details["request_id"] = apiErr.RequestID
+	}
+	result.Meta = mcp.NewMetaFromMap(map[string]any{"error": details})
+	return result
+}
+
+// errorDetails returns the controller error details toolResultError put in a result's _meta
+func errorDetails(result *mcp.CallToolResult) map[string]interface{} {
+	if result == nil || result.Meta == nil {
+		return nil
+	}
+	details, _ := result.Meta.AdditionalFields["error"].(map[string]interface{})
+	return details
+}
+
+func classifyAPIError(err error) (kind, hint string) {
+	switch {
+	case unifi.IsSiteNotFound(err):
+		return ErrorKindSiteNotFound, "The controller does not know this site. Call get_network_sites to list the current sites."
+	case unifi.IsNotFound(err):
+		return ErrorKindNotFound, "The object does not exist on this site. List the objects again to get a current ID, or check site_id."
+	case unifi.IsUnauthorized(err):
+		return ErrorKindUnauthorized, "The controller rejected the credentials. Check that the API key is valid and its user has the permissions this call needs."
+	case unifi.IsRateLimited(err):
+		return ErrorKindRateLimited, "The controller is rate limiting requests. Wait before trying again."
+	case unifi.IsValidation(err):
+		return ErrorKindValidation, "The controller rejected the request as invalid. Check the field values against the tool's input schema and the current object."
+	}
+	return ErrorKindController, ""
+}
@@ -0,0 +1,448 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+const (
+	// DefaultRolloutBatchTimeout is how long a rollout waits for a batch of upgraded devices to reconnect
+	DefaultRolloutBatchTimeout = 10 * time.Minute
+	// defaultRolloutPollInterval is how often a rollout checks whether the devices of a batch reconnected
+	defaultRolloutPollInterval = 10 * time.Second
+)
+
+// States of a device in an upgrade or rollout
+const (
+	upgradeUpToDate  = "up_to_date"
+	upgradeStarted   = "upgrading"
+	upgradeCompleted = "upgraded"
+	upgradeFailed    = "failed"
+	upgradeSkipped   = "skipped"
+)
+
+// firmwareUpgrade reports the upgrade of one device
+type firmwareUpgrade struct {
+	DeviceID    string `json:"device_id"`
+	MAC         string `json:"mac"`
+	Name        string `json:"name,omitempty"`
+	Model       string `json:"model,omitempty"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version,omitempty"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+func newFirmwareUpgrade(device unifi.NetworkDeviceFirmware) *firmwareUpgrade {
+	return &firmwareUpgrade{
+		DeviceID:    device.ID,
+		MAC:         device.MAC,
+		Name:        device.Name,
+		Model:       device.Model,
+		FromVersion: device.Version,
+		ToVersion:   device.UpgradeToFirmware,
+	}
+}
+
+// rolloutBatch reports one batch of a staged rollout
+type rolloutBatch struct {
+	Batch   int                `json:"batch"`
+	Status  string             `json:"status"`
+	Devices []*firmwareUpgrade `json:"devices"`
+}
+
+func (s *Server) getAvailableFirmware(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: get_available_firmware")
+
+	siteID := request.GetString("site_id", "")
+	model := request.GetString("model", "")
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	firmware, err := s.client(ctx).GetAvailableFirmware(ctx, site.Name)
+	if err != nil {
+		return toolResultError("Failed to get available firmware", err), nil
+	}
+
+	if model != "" {
+		matching := []unifi.NetworkFirmware{}
+		for _, release := range firmware {
+			if strings.EqualFold(release.Device, model) {
+				matching = append(matching, release)
+			}
+		}
+		firmware = matching
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"firmware": firmware,
+		"count":    len(firmware),
+		"site_id":  site.ExternalID,
+	})
+}
+
+func (s *Server) getOutdatedDevices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: get_outdated_devices")
+
+	siteID := request.GetString("site_id", "")
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	devices, err := s.client(ctx).GetDeviceFirmware(ctx, site.Name)
+	if err != nil {
+		return toolResultError("Failed to get device firmware", err), nil
+	}
+
+	outdated := []unifi.NetworkDeviceFirmware{}
+	for _, device := range devices {
+		if device.Upgradable {
+			outdated = append(outdated, device)
+		}
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"devices": outdated,
+		"count":   len(outdated),
+		"checked": len(devices),
+		"site_id": site.ExternalID,
+	})
+}
+
+func (s *Server) upgradeDeviceFirmware(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: upgrade_device_firmware")
+
+	siteID := request.GetString("site_id", "")
+	deviceID := request.GetString("device_id", "")
+	tag := request.GetString("tag", "")
+	if (deviceID == "") == (tag == "") {
+		return mcp.NewToolResultError("exactly one of device_id or tag is required"), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	var deviceIDs []string
+	if deviceID != "" {
+		deviceIDs = []string{deviceID}
+	}
+	targets, err := s.firmwareTargets(ctx, site.Name, deviceIDs, tag)
+	if err != nil {
+		return toolResultError("Failed to select devices", err), nil
+	}
+
+	upgrades := []*firmwareUpgrade{}
+	started, failed := 0, 0
+	for _, device := range targets {
+		upgrade := newFirmwareUpgrade(device)
+		upgrades = append(upgrades, upgrade)
+		if !device.Upgradable {
+			upgrade.Status = upgradeUpToDate
+			continue
+		}
+
+		if _, err := s.client(ctx).UpgradeDevice(ctx, site.Name, device.MAC); err != nil {
+			if deviceID != "" {
+				return toolResultError("Failed to upgrade device", err), nil
+			}
+			upgrade.Status = upgradeFailed
+			upgrade.Error = err.Error()
+			failed++
+			continue
+		}
+		upgrade.Status = upgradeStarted
+		started++
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"success":   failed == 0,
+		"upgrades":  upgrades,
+		"upgrading": started,
+		"failed":    failed,
+		"site_id":   site.ExternalID,
+	})
+}
+
+// rolloutFirmware upgrades the selected devices in batches. Each batch has to
+// reconnect before the next one starts; the rollout halts at the first device
+// that fails to upgrade or to come back.
+func (s *Server) rolloutFirmware(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: rollout_firmware")
+
+	siteID := request.GetString("site_id", "")
+	deviceIDs := request.GetStringSlice("device_ids", nil)
+	tag := request.GetString("tag", "")
+	if (len(deviceIDs) == 0) == (tag == "") {
+		return mcp.NewToolResultError("exactly one of device_ids or tag is required"), nil
+	}
+
+	batchSize := request.GetInt("batch_size", 1)
+	if batchSize < 1 {
+		return mcp.NewToolResultError("batch_size must be at least 1"), nil
+	}
+	batchTimeout := DefaultRolloutBatchTimeout
+	if value := request.GetString("batch_timeout", ""); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid batch_timeout %q: want a positive duration such as 10m", value)), nil
+		}
+		batchTimeout = timeout
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	targets, err := s.firmwareTargets(ctx, site.Name, deviceIDs, tag)
+	if err != nil {
+		return toolResultError("Failed to select devices", err), nil
+	}
+
+	var outdated []unifi.NetworkDeviceFirmware
+	for _, device := range targets {
+		if device.Upgradable {
+			outdated = append(outdated, device)
+		}
+	}
+
+	batches := []*rolloutBatch{}
+	haltReason := ""
+	upgraded := 0
+	for start := 0; start < len(outdated); start += batchSize {
+		batch := &rolloutBatch{Batch: len(batches) + 1}
+		batches = append(batches, batch)
+		for _, device := range outdated[start:min(start+batchSize, len(outdated))] {
+			batch.Devices = append(batch.Devices, newFirmwareUpgrade(device))
+		}
+
+		if haltReason != "" {
+			batch.Status = upgradeSkipped
+			for _, upgrade := range batch.Devices {
+				upgrade.Status = upgradeSkipped
+			}
+			continue
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"site_id": site.ExternalID,
+			"batch":   batch.Batch,
+			"devices": len(batch.Devices),
+		}).Info("Starting firmware rollout batch")
+		s.runRolloutBatch(ctx, site, batch, batchTimeout)
+
+		for _, upgrade := range batch.Devices {
+			if upgrade.Status == upgradeCompleted {
+				upgraded++
+			} else if haltReason == "" {
+				device := upgrade.MAC
+				if upgrade.Name != "" {
+					device = fmt.Sprintf("%s (%s)", upgrade.Name, upgrade.MAC)
+				}
+				haltReason = fmt.Sprintf("batch %d: %s %s: %s", batch.Batch, device, upgrade.Status, upgrade.Error)
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"status":     "completed",
+		"batches":    batches,
+		"upgraded":   upgraded,
+		"up_to_date": len(targets) - len(outdated),
+		"site_id":    site.ExternalID,
+	}
+	if haltReason != "" {
+		result["status"] = "halted"
+		result["halt_reason"] = haltReason
+	}
+	return mcp.NewToolResultJSON(result)
+}
+
+// runRolloutBatch starts the upgrade of every device of batch and waits until
+// they reconnect, recording the outcome in each device's status
+func (s *Server) runRolloutBatch(ctx context.Context, site unifi.NetworkSite, batch *rolloutBatch, timeout time.Duration) {
+	batch.Status = upgradeCompleted
+	var started []*firmwareUpgrade
+	for _, upgrade := range batch.Devices {
+		if _, err := s.client(ctx).UpgradeDevice(ctx, site.Name, upgrade.MAC); err != nil {
+			upgrade.Status = upgradeFailed
+			upgrade.Error = err.Error()
+			batch.Status = upgradeFailed
+			continue
+		}
+		upgrade.Status = upgradeStarted
+		started = append(started, upgrade)
+	}
+
+	s.waitForReconnect(ctx, site, started, timeout)
+	for _, upgrade := range started {
+		if upgrade.Status != upgradeCompleted {
+			batch.Status = upgradeFailed
+		}
+	}
+}
+
+// waitForReconnect polls GetDevices until every upgraded device has gone away
+// and come back Connected, or the timeout expires. A device that rebooted
+// between two polls is recognised by its new firmware version instead.
+func (s *Server) waitForReconnect(ctx context.Context, site unifi.NetworkSite, upgrades []*firmwareUpgrade, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	wentDown := map[string]bool{}
+	connected := map[string]bool{}
+	remaining := len(upgrades)
+
+	for remaining > 0 {
+		devices, err := s.client(ctx).GetDevices(unifi.WithoutCache(ctx), site.ExternalID)
+		if err != nil {
+			// The controller may be busy provisioning the batch; try again on the next poll
+			s.logger.WithError(err).Debug("Failed to poll devices during firmware rollout")
+		} else {
+			connected = map[string]bool{}
+			for _, device := range devices {
+				connected[strings.ToLower(device.MAC)] = device.Connected
+			}
+			for _, upgrade := range upgrades {
+				mac := strings.ToLower(upgrade.MAC)
+				if upgrade.Status != upgradeStarted {
+					continue
+				}
+				if !connected[mac] {
+					wentDown[mac] = true
+				} else if wentDown[mac] {
+					upgrade.Status = upgradeCompleted
+					remaining--
+				}
+			}
+		}
+		if remaining == 0 {
+			return
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			for _, upgrade := range upgrades {
+				if upgrade.Status == upgradeStarted {
+					upgrade.Status = upgradeFailed
+					upgrade.Error = "rollout cancelled: " + ctx.Err().Error()
+				}
+			}
+			return
+		case <-time.After(min(s.rolloutPoll, wait)):
+		}
+	}
+
+	firmware, err := s.client(ctx).GetDeviceFirmware(unifi.WithoutCache(ctx), site.Name)
+	if err != nil {
+		s.logger.WithError(err).Debug("Failed to check firmware versions after rollout batch")
+	}
+	versions := map[string]string{}
+	for _, device := range firmware {
+		versions[strings.ToLower(device.MAC)] = device.Version
+	}
+	for _, upgrade := range upgrades {
+		if upgrade.Status != upgradeStarted {
+			continue
+		}
+		mac := strings.ToLower(upgrade.MAC)
+		if version, ok := versions[mac]; ok && connected[mac] && version != upgrade.FromVersion {
+			upgrade.Status = upgradeCompleted
+			continue
+		}
+		upgrade.Status = upgradeFailed
+		upgrade.Error = fmt.Sprintf("did not reconnect with new firmware within %s", timeout)
+	}
+}
+
+// firmwareTargets returns the firmware state of the devices selected by ID or
+// MAC address, or of the members of a device tag given by ID or name. siteID
+// is the site's legacy name.
+func (s *Server) firmwareTargets(ctx context.Context, siteID string, deviceIDs []string, tag string) ([]unifi.NetworkDeviceFirmware, error) {
+	devices, err := s.client(ctx).GetDeviceFirmware(unifi.WithoutCache(ctx), siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag != "" {
+		tags, err := s.client(ctx).GetDeviceTags(ctx, siteID)
+		if err != nil {
+			return nil, err
+		}
+		var members map[string]bool
+		for _, t := range tags {
+			if t.ID == tag || strings.EqualFold(t.Name, tag) {
+				members = map[string]bool{}
+				for _, mac := range t.MemberTable {
+					members[strings.ToLower(mac)] = true
+				}
+				break
+			}
+		}
+		if members == nil {
+			return nil, fmt.Errorf("unknown device tag %q", tag)
+		}
+
+		targets := []unifi.NetworkDeviceFirmware{}
+		for _, device := range devices {
+			if members[strings.ToLower(device.MAC)] {
+				targets = append(targets, device)
+			}
+		}
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("device tag %q has no adopted devices", tag)
+		}
+		return targets, nil
+	}
+
+	targets := []unifi.NetworkDeviceFirmware{}
+	selected := map[string]bool{}
+	for _, id := range deviceIDs {
+		found := false
+		for _, device := range devices {
+			if device.ID == id || strings.EqualFold(device.MAC, id) {
+				if !selected[device.MAC] {
+					selected[device.MAC] = true
+					targets = append(targets, device)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown device %q", id)
+		}
+	}
+	return targets, nil
+}
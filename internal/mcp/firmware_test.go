@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi/unifitest"
+)
+
+func TestRolloutFirmwareHaltsOnFailure(t *testing.T) {
+	fake := unifitest.NewFakeNetwork()
+	for i, mac := range []string{"aa:00:00:00:00:01", "aa:00:00:00:00:02", "aa:00:00:00:00:03", "aa:00:00:00:00:04"} {
+		id := fmt.Sprintf("dev-%d", i+1)
+		fake.Devices = append(fake.Devices, unifi.NetworkDevice{ID: id, MAC: mac, Connected: true})
+		fake.DeviceFirmware = append(fake.DeviceFirmware, unifi.NetworkDeviceFirmware{
+			ID: id, MAC: mac, Name: "AP " + id, Version: "6.6.0", Upgradable: true, UpgradeToFirmware: "6.7.0",
+		})
+	}
+	// The third device is not connected, so it never comes back from its upgrade
+	fake.Devices[2].Connected = false
+
+	s := NewServer(fake)
+	s.rolloutPoll = time.Millisecond
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "rollout_firmware"
+	request.Params.Arguments = map[string]interface{}{
+		"device_ids":    []interface{}{"dev-1", "dev-2", "dev-3", "dev-4"},
+		"batch_size":    float64(2),
+		"batch_timeout": "20ms",
+	}
+	result, err := s.rolloutFirmware(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("rollout failed: %v %v", result, err)
+	}
+
+	data := result.StructuredContent.(map[string]interface{})
+	if data["status"] != "halted" || !strings.Contains(data["halt_reason"].(string), "batch 2: AP dev-3") {
+		t.Errorf("expected the rollout to halt in batch 2, got %v", data)
+	}
+	batches := data["batches"].([]*rolloutBatch)
+	if len(batches) != 2 || batches[0].Status != upgradeCompleted || batches[1].Status != upgradeFailed {
+		t.Fatalf("unexpected batches: %+v", batches)
+	}
+	if batches[1].Devices[0].Status != upgradeFailed || batches[1].Devices[1].Status != upgradeCompleted {
+		t.Errorf("unexpected batch 2 devices: %+v %+v", batches[1].Devices[0], batches[1].Devices[1])
+	}
+	if data["upgraded"] != 3 {
+		t.Errorf("expected 3 upgraded devices, got %v", data["upgraded"])
+	}
+
+	// With one device per batch, the devices after the failure are not touched
+	fake = unifitest.NewFakeNetwork()
+	fake.Devices = []unifi.NetworkDevice{{ID: "dev-1", MAC: "aa:00:00:00:00:01"}, {ID: "dev-2", MAC: "aa:00:00:00:00:02", Connected: true}}
+	fake.DeviceFirmware = []unifi.NetworkDeviceFirmware{
+		{ID: "dev-1", MAC: "aa:00:00:00:00:01", Version: "6.6.0", Upgradable: true, UpgradeToFirmware: "6.7.0"},
+		{ID: "dev-2", MAC: "aa:00:00:00:00:02", Version: "6.6.0", Upgradable: true, UpgradeToFirmware: "6.7.0"},
+	}
+	s = NewServer(fake)
+	s.rolloutPoll = time.Millisecond
+	request.Params.Arguments = map[string]interface{}{"device_ids": []interface{}{"dev-1", "dev-2"}, "batch_timeout": "10ms"}
+	result, _ = s.rolloutFirmware(context.Background(), request)
+	data = result.StructuredContent.(map[string]interface{})
+	batches = data["batches"].([]*rolloutBatch)
+	if data["status"] != "halted" || len(batches) != 2 || batches[1].Status != upgradeSkipped {
+		t.Errorf("expected the second batch to be skipped, got %v", data)
+	}
+	if calls := fake.CallsTo("UpgradeDevice"); len(calls) != 1 || calls[0].ID != "aa:00:00:00:00:01" || calls[0].SiteID != "default" {
+		t.Errorf("expected only the first device to be upgraded on the site named default, got %+v", calls)
+	}
+	if calls := fake.CallsTo("GetDevices"); len(calls) == 0 || calls[0].SiteID != "site-uuid-1" {
+		t.Errorf("expected the integration API to be polled with the site UUID, got %+v", calls)
+	}
+}
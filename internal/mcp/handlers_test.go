@@ -0,0 +1,286 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi/unifitest"
+)
+
+// newFakeNetwork returns a fake controller holding one object of every kind
+func newFakeNetwork() *unifitest.FakeNetwork {
+	fake := unifitest.NewFakeNetwork()
+	fake.Devices = []unifi.NetworkDevice{{ID: "dev-1", Name: "Gateway", MAC: "aa:bb:cc:00:00:01", Connected: true}}
+	fake.PendingDevices = []unifi.NetworkPendingDevice{{MACAddress: "aa:bb:cc:00:00:02", Model: "U6-Lite"}}
+	fake.DeviceTags = []unifi.NetworkDeviceTag{{ID: "tag-1", Name: "lobby", MemberTable: []string{"aa:bb:cc:00:00:01"}}}
+	fake.Clients = []unifi.NetworkConnectedClient{{ID: "client-1", Name: "laptop", MACAddress: "aa:bb:cc:00:00:03"}}
+	fake.ClientStats = []unifi.NetworkClientDevice{{MAC: "aa:bb:cc:00:00:03", Hostname: "laptop"}}
+	fake.WiFiNetworks = []unifi.NetworkWiFiNetwork{{ID: "net-1", Name: "Office", SSID: "Office"}}
+	fake.NetworkConfigs = []unifi.NetworkConfig{{ID: "net-1", Name: "Office"}}
+	fake.WiFiBroadcasts = []unifi.NetworkWiFiBroadcast{{ID: "bc-1", Name: "Office"}}
+	fake.FirewallZones = []unifi.NetworkFirewallZone{{ID: "zone-1", Name: "Internal"}}
+	fake.ACLRules = []unifi.NetworkACLRule{{ID: "acl-1", Name: "block cameras"}}
+	fake.HotspotVouchers = []unifi.NetworkHotspotVoucher{{ID: "voucher-1", Name: "lobby"}}
+	fake.TrafficRules = []unifi.NetworkTrafficRule{{ID: "rule-1"}}
+	fake.VPNServers = []unifi.NetworkVPNServer{{ID: "vpn-1", Name: "WireGuard"}}
+	fake.VPNTunnels = []unifi.NetworkVPNTunnel{{ID: "tunnel-1", Name: "Branch"}}
+	fake.WANConfig = []unifi.NetworkWANConfig{{ID: "wan-1", Name: "WAN"}}
+	fake.RADIUSProfiles = []unifi.NetworkRADIUSProfile{{ID: "radius-1", Name: "Default"}}
+	fake.DPICategories = []unifi.NetworkDPICategory{{ID: 1, Name: "Streaming"}}
+	fake.DPIApplications = []unifi.NetworkDPIApplication{{ID: 1, Name: "Netflix"}}
+	fake.AvailableFirmware = []unifi.NetworkFirmware{{Device: "UDMPRO", Version: "4.0.6"}}
+	fake.DeviceFirmware = []unifi.NetworkDeviceFirmware{{ID: "dev-1", MAC: "aa:bb:cc:00:00:01", Model: "UDMPRO", Version: "4.0.5", Upgradable: true, UpgradeToFirmware: "4.0.6"}}
+	fake.DeviceDetails = []unifi.NetworkDeviceDetail{{
+		ID: "dev-1", Name: "Gateway", MAC: "aa:bb:cc:00:00:01", State: unifi.DeviceStateConnected,
+		PortTable:     []unifi.NetworkDevicePort{{PortIdx: 1, Up: true, PoECaps: 7, PoEEnable: true}, {PortIdx: 2}},
+		PortOverrides: []unifi.NetworkPortOverride{{PortIdx: 1, Name: "Camera"}},
+	}}
+	fake.PortProfiles = []unifi.NetworkPortProfile{{ID: "profile-1", Name: "Cameras", Forward: "native"}}
+	fake.Health = unifi.NetworkHealthSubsystem{Subsystem: "wan", Status: "ok"}
+	return fake
+}
+
+func TestToolHandlers(t *testing.T) {
+	type toolCase struct {
+		args     map[string]interface{}
+		required string // argument whose absence must fail the call
+		method   string // NetworkAPI method the call must reach
+	}
+	cases := map[string]toolCase{
+		"list_controllers":              {},
+		"get_network_sites":             {method: "GetSites"},
+		"get_network_devices":           {method: "GetDevices"},
+		"get_device_detailed":           {args: map[string]interface{}{"device_id": "dev-1"}, required: "device_id", method: "GetDeviceDetailed"},
+		"get_device_stats":              {args: map[string]interface{}{"device_id": "dev-1"}, required: "device_id", method: "GetDeviceStats"},
+		"get_network_info":              {method: "GetInfo"},
+		"get_pending_devices":           {method: "GetPendingDevices"},
+		"get_wifi_networks":             {method: "GetWiFiNetworks"},
+		"get_wifi_network_detailed":     {args: map[string]interface{}{"network_id": "net-1"}, required: "network_id", method: "GetWiFiNetworkDetailed"},
+		"get_wifi_broadcasts":           {method: "GetWiFiBroadcasts"},
+		"get_network_clients":           {args: map[string]interface{}{"limit": float64(10)}, method: "GetClients"},
+		"get_client_detailed":           {args: map[string]interface{}{"mac": "aa:bb:cc:00:00:03"}, required: "mac", method: "GetClientDetailed"},
+		"get_client_stats":              {method: "GetClientStats"},
+		"get_site_health":               {method: "GetHealth"},
+		"check_network_endpoint_health": {method: "CheckEndpointHealth"},
+		"check_protect_endpoint_health": {},
+		"get_firewall_zones":            {method: "GetFirewallZones"},
+		"get_firewall_zone_detailed":    {args: map[string]interface{}{"firewall_zone_id": "zone-1"}, required: "firewall_zone_id", method: "GetFirewallZoneDetailed"},
+		"get_acl_rules":                 {method: "GetACLRules"},
+		"get_acl_rule_detailed":         {args: map[string]interface{}{"acl_rule_id": "acl-1"}, required: "acl_rule_id", method: "GetACLRuleDetailed"},
+		"get_hotspot_vouchers":          {method: "GetHotspotVouchers"},
+		"get_hotspot_voucher_detailed":  {args: map[string]interface{}{"voucher_id": "voucher-1"}, required: "voucher_id", method: "GetHotspotVoucherDetailed"},
+		"get_traffic_rules":             {method: "GetTrafficRules"},
+		"get_traffic_rule_detailed":     {args: map[string]interface{}{"traffic_matching_list_id": "rule-1"}, required: "traffic_matching_list_id", method: "GetTrafficRuleDetailed"},
+		"get_vpn_servers":               {method: "GetVPNServers"},
+		"get_device_tags":               {method: "GetDeviceTags"},
+		"get_wan_config":                {method: "GetWANConfig"},
+		"get_radius_profiles":           {method: "GetRADIUSProfiles"},
+		"get_dpi_categories":            {method: "GetDPICategories"},
+		"get_dpi_apps":                  {method: "GetDPIApplications"},
+		"get_dpi_applications":          {method: "GetDPIApplications"},
+		"get_available_firmware":        {args: map[string]interface{}{"model": "udmpro"}, method: "GetAvailableFirmware"},
+		"get_outdated_devices":          {method: "GetDeviceFirmware"},
+
+		"patch_wifi_network":    {args: map[string]interface{}{"network_id": "net-1", "settings": map[string]interface{}{"enabled": false}}, required: "network_id", method: "PatchWiFiNetwork"},
+		"patch_firewall_zone":   {args: map[string]interface{}{"zone_id": "zone-1", "settings": map[string]interface{}{"name": "IoT"}}, required: "zone_id", method: "PatchFirewallZone"},
+		"patch_acl_rule":        {args: map[string]interface{}{"rule_id": "acl-1", "settings": map[string]interface{}{"enabled": true}}, required: "rule_id", method: "PatchACLRule"},
+		"patch_hotspot_voucher": {args: map[string]interface{}{"voucher_id": "voucher-1", "settings": map[string]interface{}{"note": "lobby"}}, required: "voucher_id", method: "PatchHotspotVoucher"},
+		"patch_traffic_rule":    {args: map[string]interface{}{"rule_id": "rule-1", "settings": map[string]interface{}{"enabled": true}}, required: "rule_id", method: "PatchTrafficRule"},
+
+		"create_wifi_network":    {args: map[string]interface{}{"config": map[string]interface{}{"name": "IoT", "security": "wpapsk", "x_passphrase": "correct horse"}}, required: "config", method: "CreateWiFiNetwork"},
+		"create_firewall_zone":   {args: map[string]interface{}{"config": map[string]interface{}{"name": "IoT"}}, required: "config", method: "CreateFirewallZone"},
+		"create_acl_rule":        {args: map[string]interface{}{"config": map[string]interface{}{"name": "block", "action": "drop", "ruleset": "LAN_IN"}}, required: "config", method: "CreateACLRule"},
+		"create_hotspot_voucher": {args: map[string]interface{}{"config": map[string]interface{}{"expire": float64(60)}}, required: "config", method: "CreateHotspotVoucher"},
+		"create_traffic_rule": {args: map[string]interface{}{"config": map[string]interface{}{
+			"description": "no games", "action": "BLOCK", "matching_target": "INTERNET",
+			"target_devices": []interface{}{map[string]interface{}{"type": "ALL_CLIENTS"}},
+		}}, required: "config", method: "CreateTrafficRule"},
+		"create_vpn_tunnel": {args: map[string]interface{}{"config": map[string]interface{}{"name": "wg", "vpn_type": "wireguard-vpn"}}, required: "config", method: "CreateVPNTunnel"},
+
+		"delete_wifi_network":    {args: map[string]interface{}{"network_id": "net-1", "confirm": true}, required: "network_id", method: "DeleteWiFiNetwork"},
+		"delete_firewall_zone":   {args: map[string]interface{}{"zone_id": "zone-1", "confirm": true}, required: "zone_id", method: "DeleteFirewallZone"},
+		"delete_acl_rule":        {args: map[string]interface{}{"rule_id": "acl-1", "confirm": true}, required: "rule_id", method: "DeleteACLRule"},
+		"delete_hotspot_voucher": {args: map[string]interface{}{"voucher_id": "voucher-1", "confirm": true}, required: "voucher_id", method: "DeleteHotspotVoucher"},
+		"delete_traffic_rule":    {args: map[string]interface{}{"rule_id": "rule-1", "confirm": true}, required: "rule_id", method: "DeleteTrafficRule"},
+		"delete_vpn_tunnel":      {args: map[string]interface{}{"tunnel_id": "tunnel-1", "confirm": true}, required: "tunnel_id", method: "DeleteVPNTunnel"},
+
+		"restart_device":         {args: map[string]interface{}{"device_id": "dev-1"}, required: "device_id", method: "RestartDevice"},
+		"locate_device":          {args: map[string]interface{}{"device_id": "dev-1", "enabled": false}, required: "device_id", method: "LocateDevice"},
+		"force_provision_device": {args: map[string]interface{}{"device_id": "dev-1"}, required: "device_id", method: "ForceProvisionDevice"},
+		"adopt_device":           {args: map[string]interface{}{"mac": "AA:BB:CC:00:00:02"}, required: "mac", method: "AdoptDevice"},
+		"forget_device":          {args: map[string]interface{}{"device_id": "dev-1", "confirm": true}, required: "confirm", method: "ForgetDevice"},
+
+		"upgrade_device_firmware": {args: map[string]interface{}{"device_id": "dev-1"}, required: "device_id", method: "GetDeviceFirmware"},
+		"rollout_firmware":        {args: map[string]interface{}{"tag": "lobby", "batch_timeout": "10ms"}, required: "tag", method: "GetDeviceFirmware"},
+
+		"get_switch_ports":      {args: map[string]interface{}{"device_id": "dev-1"}, required: "device_id", method: "GetDeviceDetailed"},
+		"power_cycle_port":      {args: map[string]interface{}{"device_id": "dev-1", "port_idx": float64(1)}, required: "port_idx", method: "PowerCyclePort"},
+		"configure_switch_port": {args: map[string]interface{}{"device_id": "dev-1", "port_idx": float64(2), "enabled": false, "native_network_id": "net-1"}, required: "port_idx", method: "PatchDevice"},
+		"get_port_profiles":     {method: "GetPortProfiles"},
+		"create_port_profile":   {args: map[string]interface{}{"config": map[string]interface{}{"name": "Phones", "poe_mode": "auto"}}, required: "config", method: "CreatePortProfile"},
+		"patch_port_profile":    {args: map[string]interface{}{"profile_id": "profile-1", "settings": map[string]interface{}{"isolation": true}}, required: "profile_id", method: "PatchPortProfile"},
+		"delete_port_profile":   {args: map[string]interface{}{"profile_id": "profile-1", "confirm": true}, required: "profile_id", method: "DeletePortProfile"},
+
+		"block_client":      {args: map[string]interface{}{"mac": "AABB.CC00.0003"}, required: "mac", method: "BlockClient"},
+		"unblock_client":    {args: map[string]interface{}{"mac": "aa:bb:cc:00:00:03"}, required: "mac", method: "UnblockClient"},
+		"kick_client":       {args: map[string]interface{}{"mac": "aa:bb:cc:00:00:03"}, required: "mac", method: "KickClient"},
+		"forget_client":     {args: map[string]interface{}{"mac": "aa:bb:cc:00:00:03", "confirm": true}, required: "confirm", method: "ForgetClient"},
+		"authorize_guest":   {args: map[string]interface{}{"mac": "AA-BB-CC-00-00-03", "minutes": float64(60), "down_kbps": float64(2048), "ap_mac": "AABBCC000009"}, required: "minutes", method: "AuthorizeGuest"},
+		"unauthorize_guest": {args: map[string]interface{}{"mac": "aa:bb:cc:00:00:03"}, required: "mac", method: "UnauthorizeGuest"},
+	}
+
+	fake := newFakeNetwork()
+	s := NewServer(fake)
+	call := func(name string, args map[string]interface{}) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		request.Params.Arguments = args
+		result, err := s.logToolCall(s.toolHandlers[name])(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s: handler returned error: %v", name, err)
+		}
+		return result
+	}
+
+	for name, tool := range s.server.ListTools() {
+		tc, ok := cases[name]
+		if !ok {
+			t.Errorf("no test case for tool %s", name)
+			continue
+		}
+
+		result := call(name, tc.args)
+		if result.IsError {
+			t.Errorf("%s: unexpected error result: %v", name, result.Content)
+			continue
+		}
+
+		// The result must be a JSON object using only the fields of the output schema
+		var data map[string]interface{}
+		raw, _ := json.Marshal(result.StructuredContent)
+		if err := json.Unmarshal(raw, &data); err != nil {
+			t.Errorf("%s: result is not a JSON object: %s", name, raw)
+			continue
+		}
+		if schema := tool.Tool.OutputSchema; schema.Type != "" {
+			for key := range data {
+				if _, ok := schema.Properties[key]; !ok {
+					t.Errorf("%s: result field %q is not in the output schema", name, key)
+				}
+			}
+		}
+
+		if tc.method != "" && len(fake.CallsTo(tc.method)) == 0 {
+			t.Errorf("%s: expected a call to %s", name, tc.method)
+		}
+
+		if tc.required != "" {
+			args := map[string]interface{}{}
+			for key, value := range tc.args {
+				if key != tc.required {
+					args[key] = value
+				}
+			}
+			if result := call(name, args); !result.IsError {
+				t.Errorf("%s: expected a call without %s to fail", name, tc.required)
+			}
+		}
+	}
+
+	// Write handlers pass the site's legacy name and the object ID through
+	patches := fake.CallsTo("PatchWiFiNetwork")
+	if len(patches) != 1 || patches[0].SiteID != "default" || patches[0].ID != "net-1" || patches[0].Payload["enabled"] != false {
+		t.Errorf("unexpected PatchWiFiNetwork calls: %+v", patches)
+	}
+
+	// Device commands address the device by the MAC address looked up from its ID
+	locates := fake.CallsTo("LocateDevice")
+	if len(locates) != 1 || locates[0].ID != "aa:bb:cc:00:00:01" || locates[0].Payload["enabled"] != false {
+		t.Errorf("unexpected LocateDevice calls: %+v", locates)
+	}
+	if adopts := fake.CallsTo("AdoptDevice"); len(adopts) != 1 || adopts[0].ID != "aa:bb:cc:00:00:02" {
+		t.Errorf("unexpected AdoptDevice calls: %+v", adopts)
+	}
+
+	// Client actions normalize the MAC address and address the site by its legacy name
+	if blocks := fake.CallsTo("BlockClient"); len(blocks) != 1 || blocks[0].ID != "aa:bb:cc:00:00:03" || blocks[0].SiteID != "default" {
+		t.Errorf("unexpected BlockClient calls: %+v", blocks)
+	}
+	if auths := fake.CallsTo("AuthorizeGuest"); len(auths) != 1 || auths[0].ID != "aa:bb:cc:00:00:03" ||
+		auths[0].Payload["minutes"] != 60 || auths[0].Payload["down"] != 2048 || auths[0].Payload["ap_mac"] != "aa:bb:cc:00:00:09" {
+		t.Errorf("unexpected AuthorizeGuest calls: %+v", auths)
+	}
+
+	// Port changes send back the overrides of the other ports
+	patches = fake.CallsTo("PatchDevice")
+	if len(patches) != 1 || patches[0].ID != "dev-1" {
+		t.Fatalf("unexpected PatchDevice calls: %+v", patches)
+	}
+	overrides, _ := patches[0].Payload["port_overrides"].([]interface{})
+	if len(overrides) != 2 || overrides[0].(map[string]interface{})["name"] != "Camera" ||
+		overrides[1].(map[string]interface{})["forward"] != "disabled" || overrides[1].(map[string]interface{})["native_networkconf_id"] != "net-1" {
+		t.Errorf("unexpected port overrides: %v", overrides)
+	}
+}
+
+func TestToolHandlersReportControllerErrors(t *testing.T) {
+	fake := newFakeNetwork()
+	fake.Errors["GetDevices"] = &unifi.APIError{StatusCode: http.StatusServiceUnavailable, Method: http.MethodGet, Message: "busy"}
+	s := NewServer(fake)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_network_devices"
+	result, err := s.getNetworkDevices(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Fatalf("expected an error result, got %v %v", result, err)
+	}
+	details := errorDetails(result)
+	if details["kind"] != ErrorKindController || details["status_code"] != http.StatusServiceUnavailable {
+		t.Errorf("unexpected error details: %v", details)
+	}
+
+	// A missing object is reported as not found
+	request.Params.Name = "get_acl_rule_detailed"
+	request.Params.Arguments = map[string]interface{}{"acl_rule_id": "missing"}
+	result, _ = s.getACLRuleDetailed(context.Background(), request)
+	if details := errorDetails(result); !result.IsError || details["kind"] != ErrorKindNotFound {
+		t.Errorf("expected a not_found error, got %v", details)
+	}
+}
+
+func TestForgetDeviceDryRun(t *testing.T) {
+	fake := newFakeNetwork()
+	s := NewServer(fake)
+
+	// A dry run needs no confirm and sends nothing
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "forget_device"
+	request.Params.Arguments = map[string]interface{}{"device_id": "dev-1", "dry_run": true}
+	result, err := s.forgetDevice(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("dry run failed: %v %v", result, err)
+	}
+	data := result.StructuredContent.(map[string]interface{})
+	plan := data["request"].(unifi.RequestPlan)
+	if plan.URL != "https://unifi.test/proxy/network/api/s/default/cmd/sitemgr" || plan.Body["cmd"] != unifi.DeviceCommandForget ||
+		!reflect.DeepEqual(plan.Body["macs"], []string{"aa:bb:cc:00:00:01"}) || data["site_id"] != "site-uuid-1" {
+		t.Errorf("unexpected dry run result: %v", data)
+	}
+	if calls := fake.CallsTo("ForgetDevice"); len(calls) != 0 {
+		t.Errorf("expected no forget, got %+v", calls)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"device_id": "dev-1", "confirm": true}
+	if result, err := s.forgetDevice(context.Background(), request); err != nil || result.IsError {
+		t.Fatalf("forget failed: %v %v", result, err)
+	}
+	if calls := fake.CallsTo("ForgetDevice"); len(calls) != 1 || calls[0].SiteID != "default" {
+		t.Errorf("expected the device to be forgotten on the site named default, got %+v", calls)
+	}
+}
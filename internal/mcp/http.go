@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// httpHeartbeatInterval keeps idle Streamable HTTP GET streams alive through proxies
+	httpHeartbeatInterval = 30 * time.Second
+	// httpShutdownTimeout bounds how long in-flight requests get to finish on shutdown
+	httpShutdownTimeout = 10 * time.Second
+)
+
+// ServeHTTP starts the MCP server with the Streamable HTTP transport.
+// The MCP endpoint is served at /mcp and supports POST (JSON-RPC requests,
+// answered as JSON or an SSE stream), GET (server-to-client SSE stream) and
+// DELETE (session termination). Sessions are tracked via the Mcp-Session-Id header.
+func (s *Server) ServeHTTP(addr string, ctx context.Context) error {
+	s.logger.Infof("Starting UniFi Network MCP Server on Streamable HTTP at %s", addr)
+
+	streamable := server.NewStreamableHTTPServer(s.server,
+		server.WithEndpointPath("/mcp"),
+		server.WithHeartbeatInterval(httpHeartbeatInterval),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", s.requireAuth(streamable))
+	mux.HandleFunc("/health", s.handleHealth)
+
+	return s.listenAndServe(ctx, addr, mux)
+}
+
+// ServeSSE starts the MCP server with the legacy HTTP+SSE transport.
+// Clients open an event stream at /sse and post JSON-RPC messages to the
+// /message endpoint announced on that stream.
+func (s *Server) ServeSSE(addr string, ctx context.Context) error {
+	s.logger.Infof("Starting UniFi Network MCP Server on SSE at %s", addr)
+
+	sse := server.NewSSEServer(s.server,
+		server.WithKeepAlive(true),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/sse", s.requireAuth(sse.SSEHandler()))
+	mux.Handle("/message", s.requireAuth(sse.MessageHandler()))
+	mux.HandleFunc("/health", s.handleHealth)
+
+	return s.listenAndServe(ctx, addr, mux)
+}
+
+// requireAuth wraps handler with authentication when an Authenticator is configured.
+// The health endpoint is intentionally never wrapped.
+func (s *Server) requireAuth(handler http.Handler) http.Handler {
+	if s.auth == nil {
+		return handler
+	}
+	return s.auth.Middleware(handler)
+}
+
+// handleHealth reports liveness of the MCP server itself
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+}
+
+// listenAndServe runs an HTTP server until ctx is cancelled, then shuts it down gracefully
+func (s *Server) listenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		s.logger.Info("Shutting down HTTP server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,481 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Patterns shared by the payload schemas
+const (
+	ipv4Pattern = `^((25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])$`
+	cidrPattern = `^((25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])/([0-9]|[12][0-9]|3[0-2])$`
+	addrPattern = `^((25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])\.){3}(25[0-5]|2[0-4][0-9]|1?[0-9]?[0-9])(/([0-9]|[12][0-9]|3[0-2]))?$`
+	macPattern  = `^([0-9a-fA-F]{2}[:-]){5}[0-9a-fA-F]{2}$`
+	portPattern = `^[0-9]{1,5}(-[0-9]{1,5})?(,[0-9]{1,5}(-[0-9]{1,5})?)*$`
+)
+
+// payloadSchema describes the settings and config objects accepted by the patch_* and create_* tools of one resource
+type payloadSchema struct {
+	properties map[string]any
+	required   []string // fields a create must set
+	// check reports problems spanning several fields that JSON Schema cannot express simply
+	check func(payload map[string]interface{}, create bool) []string
+}
+
+// settingsProperty is the input schema of a patch tool's settings argument
+func (p payloadSchema) settingsProperty() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"description":          "Settings to update (required); only the listed fields are accepted",
+		"properties":           p.properties,
+		"additionalProperties": false,
+		"minProperties":        1,
+	}
+}
+
+// configProperty is the input schema of a create tool's config argument
+func (p payloadSchema) configProperty(description string) map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"description":          description + " (required); only the listed fields are accepted",
+		"properties":           p.properties,
+		"required":             p.required,
+		"additionalProperties": false,
+	}
+}
+
+// validate checks payload against the schema and returns every problem found in a single error
+func (p payloadSchema) validate(arg string, payload map[string]interface{}, create bool) error {
+	var problems []string
+	schema := p.settingsProperty()
+	if create {
+		schema = p.configProperty("")
+	}
+	validateValue(arg, schema, payload, &problems)
+	if p.check != nil {
+		for _, problem := range p.check(payload, create) {
+			problems = append(problems, arg+"."+problem)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid %s: %s", arg, strings.Join(problems, "; "))
+}
+
+// validateValue validates value against the subset of JSON Schema used by the payload schemas
+func validateValue(path string, schema map[string]any, value interface{}, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+" "+fmt.Sprintf(format, args...))
+	}
+
+	if value == nil {
+		fail("must not be null")
+		return
+	}
+
+	switch schema["type"] {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if min, ok := schema["minLength"].(int); ok && len(s) < min {
+			fail("must be at least %d characters", min)
+		}
+		if max, ok := schema["maxLength"].(int); ok && len(s) > max {
+			fail("must be at most %d characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !compiledPattern(pattern).MatchString(s) {
+			fail("has an invalid format: %q", s)
+		}
+	case "integer", "number":
+		n, ok := toFloat(value)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if schema["type"] == "integer" && n != math.Trunc(n) {
+			fail("must be a whole number")
+			return
+		}
+		if min, ok := schema["minimum"].(int); ok && n < float64(min) {
+			fail("must be at least %d", min)
+		}
+		if max, ok := schema["maximum"].(int); ok && n > float64(max) {
+			fail("must be at most %d", max)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be true or false")
+			return
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if itemSchema, ok := schema["items"].(map[string]any); ok {
+			for i, item := range items {
+				validateValue(fmt.Sprintf("%s[%d]", path, i), itemSchema, item, problems)
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		validateObject(path, schema, object, problems)
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !enumContains(enum, value) {
+		fail("must be one of %s", formatEnum(enum))
+	}
+}
+
+func validateObject(path string, schema map[string]any, object map[string]interface{}, problems *[]string) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if min, ok := schema["minProperties"].(int); ok && len(object) < min {
+		*problems = append(*problems, fmt.Sprintf("%s must set at least %d field", path, min))
+	}
+	if required, ok := schema["required"].([]string); ok {
+		for _, name := range required {
+			if _, ok := object[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s.%s is required", path, name))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldSchema, known := properties[key].(map[string]any)
+		if !known {
+			if strict, ok := schema["additionalProperties"].(bool); ok && !strict {
+				*problems = append(*problems, fmt.Sprintf("%s.%s is not a known field", path, key))
+			}
+			continue
+		}
+		validateValue(path+"."+key, fieldSchema, object[key], problems)
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func enumContains(enum []any, value interface{}) bool {
+	for _, allowed := range enum {
+		if jsonEqual(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []any) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = fmt.Sprintf("%v", value)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+var patternCache sync.Map // pattern -> *regexp.Regexp
+
+func compiledPattern(pattern string) *regexp.Regexp {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patternCache.Store(pattern, re)
+	return re
+}
+
+// toolPayloads maps each patch and create tool to the schema of its settings or config argument
+var toolPayloads = map[string]payloadSchema{
+	"patch_wifi_network":     wifiNetworkPayload,
+	"patch_firewall_zone":    firewallZonePayload,
+	"patch_acl_rule":         aclRulePayload,
+	"patch_hotspot_voucher":  hotspotVoucherPayload,
+	"patch_traffic_rule":     trafficRulePayload,
+	"create_wifi_network":    wifiNetworkPayload,
+	"create_firewall_zone":   firewallZonePayload,
+	"create_acl_rule":        aclRulePayload,
+	"create_hotspot_voucher": hotspotVoucherPayload,
+	"create_traffic_rule":    trafficRulePayload,
+	"create_vpn_tunnel":      vpnTunnelPayload,
+	"patch_port_profile":     portProfilePayload,
+	"create_port_profile":    portProfilePayload,
+}
+
+// validateToolPayload validates the payload argument of a patch or create tool call;
+// other tools are accepted as-is
+func validateToolPayload(tool string, args map[string]interface{}) error {
+	schema, ok := toolPayloads[tool]
+	if !ok {
+		return nil
+	}
+	arg, create := "settings", strings.HasPrefix(tool, "create_")
+	if create {
+		arg = "config"
+	}
+	payload, ok := args[arg].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s must be an object", arg)
+	}
+	return schema.validate(arg, payload, create)
+}
+
+// Schema building helpers
+
+func stringField(desc string) map[string]any {
+	return map[string]any{"type": "string", "description": desc}
+}
+
+func lengthField(desc string, minLength, maxLength int) map[string]any {
+	return map[string]any{"type": "string", "description": desc, "minLength": minLength, "maxLength": maxLength}
+}
+
+func patternField(desc, pattern string) map[string]any {
+	return map[string]any{"type": "string", "description": desc, "pattern": pattern}
+}
+
+func enumField(desc string, values ...string) map[string]any {
+	enum := make([]any, len(values))
+	for i, value := range values {
+		enum[i] = value
+	}
+	return map[string]any{"type": "string", "description": desc, "enum": enum}
+}
+
+func intField(desc string, minimum, maximum int) map[string]any {
+	return map[string]any{"type": "integer", "description": desc, "minimum": minimum, "maximum": maximum}
+}
+
+func boolField(desc string) map[string]any {
+	return map[string]any{"type": "boolean", "description": desc}
+}
+
+func listField(desc string, items map[string]any) map[string]any {
+	return map[string]any{"type": "array", "description": desc, "items": items}
+}
+
+func objectField(desc string, properties map[string]any) map[string]any {
+	return map[string]any{"type": "object", "description": desc, "properties": properties}
+}
+
+// Payload schemas of the writable resources
+
+var wifiNetworkPayload = payloadSchema{
+	properties: map[string]any{
+		"name":             lengthField("Network name / SSID", 1, 32),
+		"enabled":          boolField("Whether the network is enabled"),
+		"purpose":          enumField("Network purpose", "corporate", "guest", "vlan-only"),
+		"security":         enumField("Security mode", "open", "wpapsk", "wpaeap", "wep", "osen"),
+		"wpa_mode":         enumField("WPA version", "auto", "wpa1", "wpa2", "wpa3"),
+		"wpa_enc":          enumField("WPA encryption", "ccmp", "gcmp", "tkip", "auto"),
+		"wpa3_support":     boolField("Enable WPA3"),
+		"wpa3_transition":  boolField("Allow WPA2 clients alongside WPA3"),
+		"x_passphrase":     lengthField("WPA pre-shared key", 8, 63),
+		"wlan_band":        enumField("Radio band", "2g", "5g", "6g", "both"),
+		"wlan_bands":       listField("Radio bands", enumField("Radio band", "2g", "5g", "6g")),
+		"hide_ssid":        boolField("Hide the SSID from broadcasts"),
+		"is_guest":         boolField("Apply guest policies"),
+		"l2_isolation":     boolField("Isolate clients from each other"),
+		"vlan_enabled":     boolField("Tag traffic with vlan"),
+		"vlan":             intField("VLAN ID", 1, 4094),
+		"networkconf_id":   stringField("ID of the network the SSID bridges to"),
+		"usergroup_id":     stringField("User group (bandwidth profile) ID"),
+		"radiusprofile_id": stringField("RADIUS profile ID used with wpaeap security"),
+		"ip_subnet":        patternField("Gateway IP and prefix, e.g. 192.168.10.1/24", cidrPattern),
+		"dhcpd_enabled":    boolField("Run a DHCP server on the network"),
+		"dhcpd_start":      patternField("First DHCP lease address", ipv4Pattern),
+		"dhcpd_stop":       patternField("Last DHCP lease address", ipv4Pattern),
+		"dhcpd_leasetime":  intField("DHCP lease time in seconds", 60, 31536000),
+	},
+	required: []string{"name", "security"},
+	check: func(payload map[string]interface{}, create bool) []string {
+		var problems []string
+		if security, _ := payload["security"].(string); security == "wpapsk" {
+			if _, ok := payload["x_passphrase"]; !ok && create {
+				problems = append(problems, "x_passphrase is required when security is wpapsk")
+			}
+		}
+		if enabled, ok := payload["vlan_enabled"].(bool); ok && enabled && create {
+			if _, ok := payload["vlan"]; !ok {
+				problems = append(problems, "vlan is required when vlan_enabled is true")
+			}
+		}
+		return problems
+	},
+}
+
+var firewallZonePayload = payloadSchema{
+	properties: map[string]any{
+		"name":        lengthField("Zone name", 1, 64),
+		"description": lengthField("Zone description", 0, 256),
+		"network_ids": listField("IDs of the networks in the zone", stringField("Network ID")),
+	},
+	required: []string{"name"},
+}
+
+var aclRulePayload = payloadSchema{
+	properties: map[string]any{
+		"name":    lengthField("Rule name", 1, 128),
+		"enabled": boolField("Whether the rule is enabled"),
+		"action":  enumField("What happens to matching traffic", "accept", "drop", "reject"),
+		"ruleset": enumField("Rule set the rule belongs to",
+			"WAN_IN", "WAN_OUT", "WAN_LOCAL", "LAN_IN", "LAN_OUT", "LAN_LOCAL", "GUEST_IN", "GUEST_OUT", "GUEST_LOCAL",
+			"WANv6_IN", "WANv6_OUT", "WANv6_LOCAL", "LANv6_IN", "LANv6_OUT", "LANv6_LOCAL", "GUESTv6_IN", "GUESTv6_OUT", "GUESTv6_LOCAL"),
+		"rule_index":              intField("Rule position; user rules use 2000-2999 (before) or 4000-4999 (after) the predefined rules", 2000, 4999),
+		"protocol":                enumField("IP protocol", "all", "tcp", "udp", "tcp_udp", "icmp", "icmpv6"),
+		"protocol_match_excepted": boolField("Match every protocol except the selected one"),
+		"logging":                 boolField("Log matching traffic"),
+		"src_address":             patternField("Source IPv4 address or subnet", addrPattern),
+		"dst_address":             patternField("Destination IPv4 address or subnet", addrPattern),
+		"src_port":                patternField("Source ports, e.g. 80,443 or 8000-8080", portPattern),
+		"dst_port":                patternField("Destination ports, e.g. 80,443 or 8000-8080", portPattern),
+		"src_firewallgroup_ids":   listField("Source firewall group IDs", stringField("Firewall group ID")),
+		"dst_firewallgroup_ids":   listField("Destination firewall group IDs", stringField("Firewall group ID")),
+		"src_networkconf_id":      stringField("Source network ID"),
+		"dst_networkconf_id":      stringField("Destination network ID"),
+		"src_networkconf_type":    enumField("How the source network is matched", "NETv4", "ADDRv4"),
+		"dst_networkconf_type":    enumField("How the destination network is matched", "NETv4", "ADDRv4"),
+		"state_established":       boolField("Match established connections"),
+		"state_related":           boolField("Match related connections"),
+		"state_new":               boolField("Match new connections"),
+		"state_invalid":           boolField("Match invalid packets"),
+		"ipsec":                   enumField("IPsec matching", "", "match-ipsec", "match-none"),
+	},
+	required: []string{"name", "action", "ruleset"},
+}
+
+var hotspotVoucherPayload = payloadSchema{
+	properties: map[string]any{
+		"name":       lengthField("Operator or voucher name", 1, 128),
+		"note":       lengthField("Note printed with the voucher", 0, 256),
+		"n":          intField("Number of vouchers to create", 1, 10000),
+		"expire":     intField("Validity in minutes once activated", 1, 5256000),
+		"quota":      intField("Number of uses per voucher (0 for unlimited)", 0, 10000),
+		"up":         intField("Upload limit in Kbps", 2, 100000),
+		"down":       intField("Download limit in Kbps", 2, 100000),
+		"bytes":      intField("Data transfer limit in MB", 1, 1048576),
+		"x_password": lengthField("Hotspot operator password", 1, 128),
+	},
+	required: []string{"expire"},
+}
+
+var trafficRulePayload = payloadSchema{
+	properties: map[string]any{
+		"description":     lengthField("Rule description", 1, 256),
+		"enabled":         boolField("Whether the rule is enabled"),
+		"action":          enumField("What happens to matching traffic", "BLOCK", "ALLOW"),
+		"matching_target": enumField("What the rule matches", "INTERNET", "IP", "DOMAIN", "REGION", "APP", "APP_CATEGORY"),
+		"target_devices": listField("Clients or networks the rule applies to", objectField("Target", map[string]any{
+			"type":       enumField("Target type", "ALL_CLIENTS", "CLIENT", "NETWORK", "GROUP"),
+			"client_mac": patternField("Client MAC address", macPattern),
+			"network_id": stringField("Network ID"),
+		})),
+		"domains":          listField("Domains matched when matching_target is DOMAIN", objectField("Domain", map[string]any{"domain": stringField("Domain name")})),
+		"ip_addresses":     listField("Addresses matched when matching_target is IP", objectField("Address", map[string]any{"ip_or_subnet": stringField("IP address or subnet")})),
+		"regions":          listField("ISO country codes matched when matching_target is REGION", patternField("Country code", `^[A-Z]{2}$`)),
+		"app_ids":          listField("Application IDs matched when matching_target is APP", intField("Application ID", 0, math.MaxInt32)),
+		"app_category_ids": listField("Application category IDs matched when matching_target is APP_CATEGORY", intField("Category ID", 0, math.MaxInt32)),
+		"bandwidth_limit": objectField("Bandwidth limit", map[string]any{
+			"enabled":             boolField("Whether the limit applies"),
+			"download_limit_kbps": intField("Download limit in Kbps", 1, 10000000),
+			"upload_limit_kbps":   intField("Upload limit in Kbps", 1, 10000000),
+		}),
+		"schedule": objectField("When the rule is active", map[string]any{
+			"mode": enumField("Schedule mode", "ALWAYS", "EVERY_DAY", "EVERY_WEEK", "ONE_TIME_ONLY", "CUSTOM"),
+		}),
+	},
+	required: []string{"description", "action", "matching_target", "target_devices"},
+}
+
+var vpnTunnelPayload = payloadSchema{
+	properties: map[string]any{
+		"name":                   lengthField("Tunnel name", 1, 128),
+		"enabled":                boolField("Whether the tunnel is enabled"),
+		"vpn_type":               enumField("Tunnel type", "ipsec-vpn", "openvpn-vpn", "wireguard-vpn"),
+		"ipsec_peer_ip":          patternField("Public IP of the remote peer", ipv4Pattern),
+		"ipsec_local_ip":         patternField("Local WAN IP used for the tunnel", ipv4Pattern),
+		"remote_vpn_subnets":     listField("Subnets behind the remote peer", patternField("Subnet", cidrPattern)),
+		"x_ipsec_pre_shared_key": lengthField("IPsec pre-shared key", 8, 128),
+		"ipsec_key_exchange":     enumField("IKE version", "ikev1", "ikev2"),
+		"ipsec_ike_encryption":   enumField("IKE encryption", "aes128", "aes192", "aes256", "3des"),
+		"ipsec_esp_encryption":   enumField("ESP encryption", "aes128", "aes192", "aes256", "3des"),
+		"ipsec_ike_hash":         enumField("IKE hash", "sha1", "md5", "sha256", "sha384", "sha512"),
+		"ipsec_esp_hash":         enumField("ESP hash", "sha1", "md5", "sha256", "sha384", "sha512"),
+		"ipsec_ike_dh_group":     intField("IKE Diffie-Hellman group", 1, 32),
+		"ipsec_esp_dh_group":     intField("ESP Diffie-Hellman group", 1, 32),
+		"ipsec_pfs":              boolField("Enable perfect forward secrecy"),
+		"ipsec_dynamic_routing":  boolField("Use route-based (VTI) instead of policy-based routing"),
+		"route_distance":         intField("Administrative distance of the tunnel routes", 1, 255),
+	},
+	required: []string{"name", "vpn_type"},
+	check: func(payload map[string]interface{}, create bool) []string {
+		if vpnType, _ := payload["vpn_type"].(string); vpnType == "ipsec-vpn" && create {
+			var problems []string
+			for _, field := range []string{"ipsec_peer_ip", "x_ipsec_pre_shared_key", "remote_vpn_subnets"} {
+				if _, ok := payload[field]; !ok {
+					problems = append(problems, field+" is required when vpn_type is ipsec-vpn")
+				}
+			}
+			return problems
+		}
+		return nil
+	},
+}
+
+// poeModes are the PoE modes of a switch port; off cuts power permanently
+var poeModes = []string{"auto", "pasv24", "passthrough", "off"}
+
+var portProfilePayload = payloadSchema{
+	properties: map[string]any{
+		"name":                     lengthField("Profile name", 1, 128),
+		"forward":                  enumField("Which networks the port carries", "all", "native", "customize", "disabled"),
+		"native_networkconf_id":    stringField("ID of the untagged (native) network"),
+		"tagged_vlan_mgmt":         enumField("Which tagged networks the port carries", "auto", "block_all", "custom"),
+		"excluded_networkconf_ids": listField("IDs of the networks not tagged on the port", stringField("Network ID")),
+		"poe_mode":                 enumField("PoE mode", poeModes...),
+		"isolation":                boolField("Isolate the port from other isolated ports"),
+		"autoneg":                  boolField("Negotiate link speed and duplex"),
+		"speed":                    map[string]any{"type": "integer", "description": "Link speed in Mbps when autoneg is off", "enum": []any{10, 100, 1000, 2500, 5000, 10000}},
+		"full_duplex":              boolField("Full duplex when autoneg is off"),
+		"stormctrl_bcast_enabled":  boolField("Limit broadcast traffic"),
+		"stp_port_mode":            boolField("Take part in spanning tree"),
+		"lldpmed_enabled":          boolField("Advertise LLDP-MED"),
+	},
+	required: []string{"name"},
+	check: func(payload map[string]interface{}, create bool) []string {
+		if autoneg, ok := payload["autoneg"].(bool); ok && !autoneg && create {
+			if _, ok := payload["speed"]; !ok {
+				return []string{"speed is required when autoneg is false"}
+			}
+		}
+		return nil
+	},
+}
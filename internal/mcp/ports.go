@@ -0,0 +1,355 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// switchPort is the port of a switch a port tool acts on
+type switchPort struct {
+	site   unifi.NetworkSite
+	device *unifi.NetworkDeviceDetail
+	port   *unifi.NetworkDevicePort
+}
+
+// lookupSwitchPort reads device_id and port_idx and finds the port in the
+// switch's port table. On failure it returns the tool result to answer with.
+func (s *Server) lookupSwitchPort(ctx context.Context, request mcp.CallToolRequest) (*switchPort, *mcp.CallToolResult) {
+	siteID := request.GetString("site_id", "")
+	deviceID := request.GetString("device_id", "")
+	portIdx := request.GetInt("port_idx", 0)
+	if deviceID == "" {
+		return nil, mcp.NewToolResultError("device_id is required")
+	}
+	if portIdx < 1 {
+		return nil, mcp.NewToolResultError("port_idx is required and starts at 1")
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return nil, toolResultError("Authentication failed", err)
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return nil, toolResultError("Failed to resolve site ID", err)
+	}
+
+	device, err := s.client(ctx).GetDeviceDetailed(ctx, site, deviceID)
+	if err != nil {
+		return nil, toolResultError("Failed to get device", err)
+	}
+	for i := range device.PortTable {
+		if device.PortTable[i].PortIdx == portIdx {
+			return &switchPort{site: site, device: device, port: &device.PortTable[i]}, nil
+		}
+	}
+	return nil, mcp.NewToolResultError(fmt.Sprintf("device %s has no port %d", deviceID, portIdx))
+}
+
+func (s *Server) getSwitchPorts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: get_switch_ports")
+
+	siteID := request.GetString("site_id", "")
+	deviceID := request.GetString("device_id", "")
+	if deviceID == "" {
+		return mcp.NewToolResultError("device_id is required"), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	device, err := s.client(ctx).GetDeviceDetailed(ctx, site, deviceID)
+	if err != nil {
+		return toolResultError("Failed to get device", err), nil
+	}
+	if len(device.PortTable) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("device %s has no switch ports", deviceID)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"ports":          device.PortTable,
+		"port_overrides": device.PortOverrides,
+		"count":          len(device.PortTable),
+		"device_id":      deviceID,
+		"mac":            device.MAC,
+		"site_id":        site.ExternalID,
+	})
+}
+
+func (s *Server) powerCyclePort(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: power_cycle_port")
+
+	target, errResult := s.lookupSwitchPort(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+	if !target.port.PoEEnable {
+		return mcp.NewToolResultError(fmt.Sprintf("port %d does not supply PoE power", target.port.PortIdx)), nil
+	}
+
+	deviceID := request.GetString("device_id", "")
+	if request.GetBool("dry_run", false) {
+		return mcp.NewToolResultJSON(map[string]interface{}{
+			"dry_run":   true,
+			"request":   s.client(ctx).PlanPowerCyclePort(target.site.Name, target.device.MAC, target.port.PortIdx),
+			"port":      target.port,
+			"device_id": deviceID,
+			"site_id":   target.site.ExternalID,
+		})
+	}
+
+	ack, err := s.client(ctx).PowerCyclePort(ctx, target.site.Name, target.device.MAC, target.port.PortIdx)
+	if err != nil {
+		return toolResultError("Failed to power cycle port", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"success":         true,
+		"action":          "power_cycle",
+		"acknowledgement": ack,
+		"device_id":       deviceID,
+		"mac":             target.device.MAC,
+		"port_idx":        target.port.PortIdx,
+		"site_id":         target.site.ExternalID,
+	})
+}
+
+// configureSwitchPort changes one port of a switch through the device's
+// port_overrides. The controller replaces the whole list, so the other ports'
+// overrides are sent back unchanged.
+func (s *Server) configureSwitchPort(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: configure_switch_port")
+
+	changes := map[string]interface{}{}
+	for arg, field := range map[string]string{
+		"name":              "name",
+		"port_profile_id":   "portconf_id",
+		"native_network_id": "native_networkconf_id",
+		"poe_mode":          "poe_mode",
+	} {
+		if value := request.GetString(arg, ""); value != "" {
+			changes[field] = value
+		}
+	}
+	enabled, setEnabled := request.GetArguments()["enabled"].(bool)
+	if len(changes) == 0 && !setEnabled {
+		return mcp.NewToolResultError("set at least one of enabled, port_profile_id, native_network_id, name or poe_mode"), nil
+	}
+	if mode, ok := changes["poe_mode"].(string); ok && !slices.Contains(poeModes, mode) {
+		return mcp.NewToolResultError(fmt.Sprintf("poe_mode must be one of %v", poeModes)), nil
+	}
+
+	target, errResult := s.lookupSwitchPort(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+	if _, ok := changes["poe_mode"]; ok && target.port.PoECaps == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("port %d does not support PoE", target.port.PortIdx)), nil
+	}
+
+	current, err := portOverrideList(target.device.PortOverrides)
+	if err != nil {
+		return toolResultError("Failed to encode port overrides", err), nil
+	}
+	overrides := copyPortOverrides(current)
+	override := findPortOverride(overrides, target.port.PortIdx)
+	if override == nil {
+		override = map[string]interface{}{"port_idx": target.port.PortIdx}
+		overrides = append(overrides, override)
+		sort.SliceStable(overrides, func(i, j int) bool {
+			a, _ := toFloat(overrides[i].(map[string]interface{})["port_idx"])
+			b, _ := toFloat(overrides[j].(map[string]interface{})["port_idx"])
+			return a < b
+		})
+	}
+	for field, value := range changes {
+		override[field] = value
+	}
+	if setEnabled {
+		if !enabled {
+			override["forward"] = "disabled"
+		} else if override["forward"] == "disabled" {
+			// Without the override the port carries what its profile says again
+			delete(override, "forward")
+		}
+	}
+
+	settings := map[string]interface{}{"port_overrides": overrides}
+	deviceID := request.GetString("device_id", "")
+	if request.GetBool("dry_run", false) {
+		return s.planPatchFrom(ctx, target.site, unifi.ResourceDevice, target.device.ID, settings,
+			map[string]interface{}{"port_overrides": current})
+	}
+
+	if _, err := s.client(ctx).PatchDevice(ctx, target.site.Name, target.device.ID, settings); err != nil {
+		return toolResultError("Failed to configure switch port", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"success":       true,
+		"port_override": override,
+		"device_id":     deviceID,
+		"port_idx":      target.port.PortIdx,
+		"site_id":       target.site.ExternalID,
+	})
+}
+
+// portOverrideList converts port overrides into the generic JSON the controller
+// is sent, keeping the fields the models do not declare
+func portOverrideList(overrides []unifi.NetworkPortOverride) ([]interface{}, error) {
+	list := make([]interface{}, 0, len(overrides)+1)
+	for _, override := range overrides {
+		m, err := unifi.ToMap(override)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+// copyPortOverrides copies a port override list and its entries, so the copy's
+// overrides can be changed while the original still shows the current state
+func copyPortOverrides(overrides []interface{}) []interface{} {
+	copied := make([]interface{}, len(overrides), len(overrides)+1)
+	for i, entry := range overrides {
+		if override, ok := entry.(map[string]interface{}); ok {
+			entry = maps.Clone(override)
+		}
+		copied[i] = entry
+	}
+	return copied
+}
+
+func findPortOverride(overrides []interface{}, portIdx int) map[string]interface{} {
+	for _, entry := range overrides {
+		override, _ := entry.(map[string]interface{})
+		if idx, ok := toFloat(override["port_idx"]); ok && int(idx) == portIdx {
+			return override
+		}
+	}
+	return nil
+}
+
+func (s *Server) getPortProfiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: get_port_profiles")
+
+	siteID := request.GetString("site_id", "")
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	profiles, err := s.client(ctx).GetPortProfiles(ctx, site.Name)
+	if err != nil {
+		return toolResultError("Failed to get port profiles", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"profiles": profiles,
+		"count":    len(profiles),
+		"site_id":  site.ExternalID,
+	})
+}
+
+func (s *Server) createPortProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: create_port_profile")
+
+	siteID := request.GetString("site_id", "")
+	args := request.GetArguments()
+	config, ok := args["config"].(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("config must be an object"), nil
+	}
+	if err := portProfilePayload.validate("config", config, true); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planCreate(ctx, site, unifi.ResourcePortProfile, config)
+	}
+
+	result, err := s.client(ctx).CreatePortProfile(ctx, site.Name, config)
+	if err != nil {
+		return toolResultError("Failed to create port profile", err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"success": true,
+		"profile": result,
+		"site_id": site.ExternalID,
+	})
+}
+
+func (s *Server) patchPortProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: patch_port_profile")
+
+	siteID := request.GetString("site_id", "")
+	profileID := request.GetString("profile_id", "")
+	args := request.GetArguments()
+	settings, ok := args["settings"].(map[string]interface{})
+	if !ok {
+		return mcp.NewToolResultError("settings must be an object"), nil
+	}
+	if err := portProfilePayload.validate("settings", settings, false); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if profileID == "" {
+		return mcp.NewToolResultError("profile_id is required"), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planPatch(ctx, site, unifi.ResourcePortProfile, profileID, settings)
+	}
+
+	result, err := s.client(ctx).PatchPortProfile(ctx, site.Name, profileID, settings)
+	if err != nil {
+		return toolResultError("Failed to update port profile", err), nil
+	}
+
+	result["success"] = true
+	result["profile_id"] = profileID
+	result["site_id"] = site.ExternalID
+	return mcp.NewToolResultJSON(result)
+}
+
+func (s *Server) deletePortProfile(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: delete_port_profile")
+	return s.deleteObject(ctx, request, "profile_id", unifi.ResourcePortProfile, s.client(ctx).DeletePortProfile)
+}
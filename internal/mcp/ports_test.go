@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+func TestConfigureSwitchPortDryRun(t *testing.T) {
+	fake := newFakeNetwork()
+	fake.DeviceDetails[0].PortOverrides = []unifi.NetworkPortOverride{{PortIdx: 1, Name: "Camera", Forward: "disabled"}}
+	s := NewServer(fake)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "configure_switch_port"
+	request.Params.Arguments = map[string]interface{}{"device_id": "dev-1", "port_idx": float64(1), "enabled": true, "dry_run": true}
+	result, err := s.configureSwitchPort(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("dry run failed: %v %v", result, err)
+	}
+
+	data := result.StructuredContent.(map[string]interface{})
+	plan := data["request"].(unifi.RequestPlan)
+	if plan.Method != "PATCH" || plan.URL != "https://unifi.test/proxy/network/api/s/default/rest/device/dev-1" {
+		t.Errorf("unexpected request plan: %+v", plan)
+	}
+	override := plan.Body["port_overrides"].([]interface{})[0].(map[string]interface{})
+	if _, ok := override["forward"]; ok || override["name"] != "Camera" {
+		t.Errorf("expected enabling the port to drop only its forward override, got %v", override)
+	}
+	if data["changed_count"] != 1 || len(fake.CallsTo("PatchDevice")) != 0 {
+		t.Errorf("expected one planned change and no write, got %v", data)
+	}
+	current := data["current"].(map[string]interface{})["port_overrides"].([]interface{})[0].(map[string]interface{})
+	if current["forward"] != "disabled" {
+		t.Errorf("expected the current overrides to be left unchanged, got %v", current)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"device_id": "dev-1", "port_idx": float64(1), "enabled": true}
+	if result, err := s.configureSwitchPort(context.Background(), request); err != nil || result.IsError {
+		t.Fatalf("configure failed: %v %v", result, err)
+	}
+	if patches := fake.CallsTo("PatchDevice"); len(patches) != 1 || patches[0].SiteID != "default" || patches[0].ID != "dev-1" {
+		t.Errorf("expected the device to be patched on the site named default, got %+v", patches)
+	}
+}
+
+func TestPowerCyclePortRequiresPoE(t *testing.T) {
+	fake := newFakeNetwork()
+	s := NewServer(fake)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "power_cycle_port"
+	for portIdx, message := range map[float64]string{2: "port 2 does not supply PoE power", 9: "device dev-1 has no port 9"} {
+		request.Params.Arguments = map[string]interface{}{"device_id": "dev-1", "port_idx": portIdx}
+		result, _ := s.powerCyclePort(context.Background(), request)
+		if !result.IsError || result.Content[0].(mcp.TextContent).Text != message {
+			t.Errorf("port %v: expected %q, got %v", portIdx, message, result.Content)
+		}
+	}
+	if calls := fake.CallsTo("PowerCyclePort"); len(calls) != 0 {
+		t.Errorf("expected no power cycle, got %+v", calls)
+	}
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// defaultRolesClaim is the JWT claim that carries role names
+const defaultRolesClaim = "roles"
+
+// Role declares which tools and sites a caller may use. Entries are glob
+// patterns as understood by path.Match, e.g. "get_*" or "branch-*".
+type Role struct {
+	Tools []string `json:"tools"`
+	Sites []string `json:"sites"`
+}
+
+// RBACConfig maps authenticated callers to roles
+type RBACConfig struct {
+	Roles        map[string]Role     `json:"roles"`
+	Bindings     map[string][]string `json:"bindings"`      // token name or JWT subject -> role names
+	DefaultRoles []string            `json:"default_roles"` // roles for authenticated callers without a binding
+	RolesClaim   string              `json:"roles_claim"`   // JWT claim listing role names (default "roles")
+}
+
+// LoadRBACConfig reads role definitions and bindings from a JSON file
+func LoadRBACConfig(path string) (*RBACConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read roles file: %w", err)
+	}
+
+	var cfg RBACConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse roles file: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Authorizer decides which tools and sites an authenticated caller may use
+type Authorizer struct {
+	roles        map[string]Role
+	bindings     map[string][]string
+	defaultRoles []string
+	rolesClaim   string
+}
+
+// NewAuthorizer validates cfg and creates an Authorizer from it
+func NewAuthorizer(cfg RBACConfig) (*Authorizer, error) {
+	if len(cfg.Roles) == 0 {
+		return nil, fmt.Errorf("no roles defined")
+	}
+
+	for name, role := range cfg.Roles {
+		for _, pattern := range append(append([]string{}, role.Tools...), role.Sites...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("role %q has invalid pattern %q: %w", name, pattern, err)
+			}
+		}
+	}
+
+	checkRoles := func(context string, names []string) error {
+		for _, name := range names {
+			if _, ok := cfg.Roles[name]; !ok {
+				return fmt.Errorf("%s references unknown role %q", context, name)
+			}
+		}
+		return nil
+	}
+	for subject, names := range cfg.Bindings {
+		if err := checkRoles(fmt.Sprintf("binding for %q", subject), names); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkRoles("default_roles", cfg.DefaultRoles); err != nil {
+		return nil, err
+	}
+
+	rolesClaim := cfg.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = defaultRolesClaim
+	}
+
+	return &Authorizer{
+		roles:        cfg.Roles,
+		bindings:     cfg.Bindings,
+		defaultRoles: cfg.DefaultRoles,
+		rolesClaim:   rolesClaim,
+	}, nil
+}
+
+// RolesFor returns the sorted role names granted to id. Roles come from the
+// subject's binding plus any known roles listed in the JWT roles claim; callers
+// with neither fall back to the default roles.
+func (a *Authorizer) RolesFor(id *Identity) []string {
+	granted := make(map[string]bool)
+	for _, name := range a.bindings[id.Subject] {
+		granted[name] = true
+	}
+
+	if id.Method == AuthMethodJWT {
+		for _, name := range claimStrings(id.Claims[a.rolesClaim]) {
+			if _, ok := a.roles[name]; ok {
+				granted[name] = true
+			}
+		}
+	}
+
+	if len(granted) == 0 {
+		for _, name := range a.defaultRoles {
+			granted[name] = true
+		}
+	}
+
+	roles := make([]string, 0, len(granted))
+	for name := range granted {
+		roles = append(roles, name)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// CanUseTool reports whether any of the caller's roles permits the tool
+func (a *Authorizer) CanUseTool(id *Identity, tool string) bool {
+	for _, name := range a.RolesFor(id) {
+		if matchAny(a.roles[name].Tools, tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUseSite reports whether any of the caller's roles permits the site.
+// Site patterns are matched case-insensitively against the site name, description, ID and external ID.
+func (a *Authorizer) CanUseSite(id *Identity, site unifi.NetworkSite) bool {
+	candidates := []string{site.Name, site.Desc, site.ID, site.ExternalID}
+	for _, name := range a.RolesFor(id) {
+		for _, candidate := range candidates {
+			if candidate != "" && matchAnyFold(a.roles[name].Sites, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParsePatternList parses a comma-separated list of glob patterns
+func ParsePatternList(list string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(list, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// matchAny reports whether name matches any of the glob patterns
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyFold is matchAny ignoring case
+func matchAnyFold(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// claimStrings normalises a JWT claim holding either a string or a list of strings
+func claimStrings(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, entry := range v {
+			if s, ok := entry.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi/unifitest"
+)
+
+func testAuthorizer(t *testing.T) *Authorizer {
+	t.Helper()
+	authz, err := NewAuthorizer(RBACConfig{
+		Roles: map[string]Role{
+			"viewer":   {Tools: []string{"get_*", "check_*"}, Sites: []string{"*"}},
+			"operator": {Tools: []string{"get_*", "patch_wifi_network"}, Sites: []string{"branch-*"}},
+			"admin":    {Tools: []string{"*"}, Sites: []string{"*"}},
+		},
+		Bindings: map[string][]string{
+			"helpdesk": {"viewer"},
+			"noc":      {"operator"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthorizer failed: %v", err)
+	}
+	return authz
+}
+
+func TestAuthorizerTools(t *testing.T) {
+	authz := testAuthorizer(t)
+	helpdesk := &Identity{Subject: "helpdesk", Method: AuthMethodToken}
+	jwtAdmin := &Identity{Subject: "alice", Method: AuthMethodJWT, Claims: map[string]interface{}{"roles": []interface{}{"admin"}}}
+	unbound := &Identity{Subject: "stranger", Method: AuthMethodToken}
+
+	tests := []struct {
+		id   *Identity
+		tool string
+		want bool
+	}{
+		{helpdesk, "get_network_clients", true},
+		{helpdesk, "patch_acl_rule", false},
+		{helpdesk, "create_vpn_tunnel", false},
+		{jwtAdmin, "create_vpn_tunnel", true},
+		{unbound, "get_network_clients", false},
+	}
+	for _, tt := range tests {
+		if got := authz.CanUseTool(tt.id, tt.tool); got != tt.want {
+			t.Errorf("CanUseTool(%s, %s) = %v, want %v", tt.id.Subject, tt.tool, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizerSites(t *testing.T) {
+	authz := testAuthorizer(t)
+	noc := &Identity{Subject: "noc", Method: AuthMethodToken}
+
+	if !authz.CanUseSite(noc, unifi.NetworkSite{Name: "Branch-Oslo"}) {
+		t.Error("expected operator to use branch site")
+	}
+	if authz.CanUseSite(noc, unifi.NetworkSite{Name: "hq"}) {
+		t.Error("expected operator to be denied hq site")
+	}
+}
+
+func TestNewAuthorizerRejectsUnknownRole(t *testing.T) {
+	_, err := NewAuthorizer(RBACConfig{
+		Roles:    map[string]Role{"viewer": {Tools: []string{"get_*"}}},
+		Bindings: map[string][]string{"helpdesk": {"superuser"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for binding to unknown role")
+	}
+}
+
+func TestAuthorizeToolCallMiddleware(t *testing.T) {
+	s := newTestServer(WithAuthorizer(testAuthorizer(t)))
+
+	called := false
+	handler := s.authorizeToolCall(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "patch_acl_rule"
+	ctx := WithIdentity(context.Background(), &Identity{Subject: "helpdesk", Method: AuthMethodToken})
+
+	result, err := handler(ctx, request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called || !result.IsError {
+		t.Error("expected patch_acl_rule to be denied for helpdesk")
+	}
+}
+
+func TestSiteRestrictionsApplyToPendingChangesAndDevices(t *testing.T) {
+	fake := unifitest.NewFakeNetwork()
+	fake.Sites = []unifi.NetworkSite{
+		{ID: "s1", Name: "branch-oslo", ExternalID: "site-uuid-1"},
+		{ID: "s2", Name: "hq", ExternalID: "site-uuid-2"},
+	}
+	fake.PendingDevices = []unifi.NetworkPendingDevice{{MACAddress: "aa:bb:cc:00:00:09", Model: "U6-Lite"}}
+
+	queue, err := NewChangeQueue(filepath.Join(t.TempDir(), "changes.json"))
+	if err != nil {
+		t.Fatalf("NewChangeQueue failed: %v", err)
+	}
+	requester := &Identity{Subject: "agent", Method: AuthMethodToken}
+	for _, site := range []string{"branch-oslo", "hq"} {
+		if _, err := queue.Add("patch_wifi_network", map[string]interface{}{"site_id": site, "network_id": "net-1"}, requester); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	s := NewServer(fake, WithAuthorizer(testAuthorizer(t)), WithApprovalQueue(queue, []string{"patch_wifi_network"}))
+
+	noc := WithIdentity(context.Background(), &Identity{Subject: "noc", Method: AuthMethodToken})
+	helpdesk := WithIdentity(context.Background(), &Identity{Subject: "helpdesk", Method: AuthMethodToken})
+
+	result, err := s.listPendingChanges(noc, mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("list_pending_changes failed: %v %v", result, err)
+	}
+	var listed struct {
+		Changes []PendingChange `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &listed); err != nil {
+		t.Fatalf("failed to decode changes: %v", err)
+	}
+	if len(listed.Changes) != 1 || listed.Changes[0].Arguments["site_id"] != "branch-oslo" {
+		t.Errorf("expected only the branch change for noc, got %+v", listed.Changes)
+	}
+
+	// Pending devices belong to no site yet, so they need access to every site
+	if result, _ := s.getPendingDevices(noc, mcp.CallToolRequest{}); !result.IsError {
+		t.Errorf("expected pending devices to be refused to a branch-only caller, got %v", result)
+	}
+	if result, _ := s.getPendingDevices(helpdesk, mcp.CallToolRequest{}); result.IsError {
+		t.Errorf("expected pending devices for a caller permitted every site, got %v", result)
+	}
+}
@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"encoding/json"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// Result fields shared by the output schemas
+var (
+	countField  = map[string]any{"type": "integer", "description": "Number of items returned"}
+	siteIDField = map[string]any{"type": "string", "description": "Resolved site ID"}
+	retryField  = map[string]any{"type": "integer", "description": "Number of controller requests that were retried (omitted when none were)"}
+)
+
+// outputSchemas returns the output schema of every read tool whose result is built from the typed unifi models
+func outputSchemas() map[string]mcp.ToolOutputSchema {
+	siteList := func(key string, items map[string]any) mcp.ToolOutputSchema {
+		return resultSchema(map[string]any{key: arrayOf(items), "count": countField, "site_id": siteIDField})
+	}
+	list := func(key string, items map[string]any) mcp.ToolOutputSchema {
+		return resultSchema(map[string]any{key: arrayOf(items), "count": countField})
+	}
+
+	return map[string]mcp.ToolOutputSchema{
+		"list_controllers": list("controllers", map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":            map[string]any{"type": "string"},
+				"description":     map[string]any{"type": "string"},
+				"base_url":        map[string]any{"type": "string"},
+				"controller_type": map[string]any{"type": "string", "enum": []string{"auto", "unifi-os", "classic"}},
+				"read_only":       map[string]any{"type": "boolean"},
+				"default":         map[string]any{"type": "boolean"},
+			},
+		}),
+		"get_network_sites":   list("sites", modelSchema[unifi.NetworkSite]()),
+		"get_network_devices": siteList("devices", modelSchema[unifi.NetworkDevice]()),
+		"get_device_detailed": resultSchema(map[string]any{
+			"device":    modelSchema[unifi.NetworkDeviceDetail](),
+			"summary":   modelSchema[unifi.NetworkDevice](),
+			"site_id":   siteIDField,
+			"device_id": map[string]any{"type": "string"},
+		}),
+		"get_device_stats": resultSchema(map[string]any{
+			"stats":     modelSchema[unifi.NetworkDevice](),
+			"site_id":   siteIDField,
+			"device_id": map[string]any{"type": "string"},
+		}),
+		"get_network_info":          objectSchema[unifi.NetworkApplicationInfo](),
+		"get_pending_devices":       list("devices", modelSchema[unifi.NetworkPendingDevice]()),
+		"get_wifi_networks":         siteList("networks", modelSchema[unifi.NetworkWiFiNetwork]()),
+		"get_wifi_network_detailed": objectSchema[unifi.NetworkConfig](),
+		"get_wifi_broadcasts":       siteList("broadcasts", modelSchema[unifi.NetworkWiFiBroadcast]()),
+		"get_network_clients": resultSchema(map[string]any{
+			"clients": arrayOf(modelSchema[unifi.NetworkConnectedClient]()),
+			"count":   countField,
+			"site_id": siteIDField,
+			"limit":   map[string]any{"type": "integer"},
+			"offset":  map[string]any{"type": "integer"},
+		}),
+		"get_client_detailed": resultSchema(map[string]any{
+			"client":  modelSchema[unifi.NetworkConnectedClient](),
+			"site_id": siteIDField,
+			"mac":     map[string]any{"type": "string"},
+		}),
+		"get_client_stats": resultSchema(map[string]any{
+			"stats":   arrayOf(modelSchema[unifi.NetworkClientDevice]()),
+			"site_id": siteIDField,
+		}),
+		"get_site_health": resultSchema(map[string]any{
+			"health":  modelSchema[unifi.NetworkHealthSubsystem](),
+			"site_id": siteIDField,
+		}),
+		"get_firewall_zones":           siteList("zones", modelSchema[unifi.NetworkFirewallZone]()),
+		"get_firewall_zone_detailed":   objectSchema[unifi.NetworkFirewallZone](),
+		"get_acl_rules":                siteList("rules", modelSchema[unifi.NetworkACLRule]()),
+		"get_acl_rule_detailed":        objectSchema[unifi.NetworkACLRule](),
+		"get_hotspot_vouchers":         siteList("vouchers", modelSchema[unifi.NetworkHotspotVoucher]()),
+		"get_hotspot_voucher_detailed": objectSchema[unifi.NetworkHotspotVoucher](),
+		"get_traffic_rules":            siteList("rules", modelSchema[unifi.NetworkTrafficRule]()),
+		"get_traffic_rule_detailed":    objectSchema[unifi.NetworkTrafficRule](),
+		"get_vpn_servers":              siteList("servers", modelSchema[unifi.NetworkVPNServer]()),
+		"get_device_tags":              siteList("tags", modelSchema[unifi.NetworkDeviceTag]()),
+		"get_wan_config":               siteList("wans", modelSchema[unifi.NetworkWANConfig]()),
+		"get_radius_profiles":          siteList("profiles", modelSchema[unifi.NetworkRADIUSProfile]()),
+		"get_dpi_categories":           list("categories", modelSchema[unifi.NetworkDPICategory]()),
+		"get_dpi_apps":                 list("apps", modelSchema[unifi.NetworkDPIApplication]()),
+		"get_dpi_applications":         list("applications", modelSchema[unifi.NetworkDPIApplication]()),
+		"get_available_firmware":       siteList("firmware", modelSchema[unifi.NetworkFirmware]()),
+		"get_outdated_devices": resultSchema(map[string]any{
+			"devices": arrayOf(modelSchema[unifi.NetworkDeviceFirmware]()),
+			"count":   countField,
+			"checked": map[string]any{"type": "integer", "description": "Number of devices whose firmware was checked"},
+			"site_id": siteIDField,
+		}),
+		"get_switch_ports": resultSchema(map[string]any{
+			"ports":          arrayOf(modelSchema[unifi.NetworkDevicePort]()),
+			"port_overrides": arrayOf(modelSchema[unifi.NetworkPortOverride]()),
+			"count":          countField,
+			"device_id":      map[string]any{"type": "string"},
+			"mac":            map[string]any{"type": "string"},
+			"site_id":        siteIDField,
+		}),
+		"get_port_profiles": siteList("profiles", modelSchema[unifi.NetworkPortProfile]()),
+	}
+}
+
+// resultSchema describes a tool result object with the given properties
+func resultSchema(properties map[string]any) mcp.ToolOutputSchema {
+	properties["retries"] = retryField
+	return mcp.ToolOutputSchema{Type: "object", Properties: properties}
+}
+
+// objectSchema describes a tool result that is a single model of type T
+func objectSchema[T any]() mcp.ToolOutputSchema {
+	schema := modelSchema[T]()
+	properties, _ := schema["properties"].(map[string]any)
+	return resultSchema(properties)
+}
+
+func arrayOf(items map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": items}
+}
+
+// modelSchema reflects the JSON schema of model type T. No property is marked
+// required, since the controller omits fields freely, and unknown properties are
+// allowed because models carry them in Extra.
+func modelSchema[T any]() map[string]any {
+	reflector := jsonschema.Reflector{
+		DoNotReference:             true,
+		Anonymous:                  true,
+		AllowAdditionalProperties:  true,
+		RequiredFromJSONSchemaTags: true,
+	}
+	var zero T
+	schema := reflector.Reflect(&zero)
+	schema.Version = ""
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return map[string]any{"type": "object"}
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return map[string]any{"type": "object"}
+	}
+	return out
+}
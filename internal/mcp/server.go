@@ -4,8 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -15,65 +16,284 @@ import (
 
 // Server represents the MCP server
 type Server struct {
-	networkClient *unifi.NetworkClient
+	networkClient unifi.NetworkAPI
 	server        *server.MCPServer
+	auth          *Authenticator
+	authz         *Authorizer
+	readOnly      bool
+	toolsAllow    []string
+	toolsDeny     []string
+	approvals     *ChangeQueue
+	approvalTools []string
+	audit         *AuditLog
+	controllers   map[string]*Controller
+	defaultCtrl   string // controller for calls without a controller argument
+	siteTTL       time.Duration
+	sites         map[unifi.NetworkAPI]*siteDirectory
+	sitesMu       sync.Mutex
+	rolloutPoll   time.Duration // how often firmware rollouts poll device state
+	exposed       map[string]mcp.Tool
+	toolHandlers  map[string]server.ToolHandlerFunc
 	logger        *logrus.Entry
 }
 
+// ServerOption configures optional Server behaviour
+type ServerOption func(*Server)
+
+// WithAuthenticator requires HTTP transport callers to authenticate
+func WithAuthenticator(auth *Authenticator) ServerOption {
+	return func(s *Server) {
+		s.auth = auth
+	}
+}
+
+// WithAuthorizer restricts authenticated callers to the tools and sites of their roles
+func WithAuthorizer(authz *Authorizer) ServerOption {
+	return func(s *Server) {
+		s.authz = authz
+	}
+}
+
+// WithReadOnly hides every tool that changes controller state
+func WithReadOnly(readOnly bool) ServerOption {
+	return func(s *Server) {
+		s.readOnly = readOnly
+	}
+}
+
+// WithToolPatterns limits the exposed tools. When allow is non-empty only tools
+// matching one of its glob patterns are exposed; tools matching deny are always hidden.
+func WithToolPatterns(allow, deny []string) ServerOption {
+	return func(s *Server) {
+		s.toolsAllow = allow
+		s.toolsDeny = deny
+	}
+}
+
+// WithApprovalQueue queues calls to write tools matching the glob patterns in
+// tools until another person approves them with approve_change
+func WithApprovalQueue(queue *ChangeQueue, tools []string) ServerOption {
+	return func(s *Server) {
+		s.approvals = queue
+		s.approvalTools = tools
+	}
+}
+
+// WithAuditLog exposes the audit log of controller writes through get_audit_log
+func WithAuditLog(audit *AuditLog) ServerOption {
+	return func(s *Server) {
+		s.audit = audit
+	}
+}
+
 // NewServer creates a new MCP server
-func NewServer(networkClient *unifi.NetworkClient) *Server {
+func NewServer(networkClient unifi.NetworkAPI, opts ...ServerOption) *Server {
 	s := &Server{
 		networkClient: networkClient,
-		server:        server.NewMCPServer("unifi-network-mcp", "0.1.0"),
+		exposed:       make(map[string]mcp.Tool),
+		toolHandlers:  make(map[string]server.ToolHandlerFunc),
+		siteTTL:       DefaultSiteCacheTTL,
+		rolloutPoll:   defaultRolloutPollInterval,
 		logger:        logrus.WithField("component", "MCPServer"),
 	}
 
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.controllers == nil {
+		s.controllers = map[string]*Controller{
+			DefaultControllerName: {Name: DefaultControllerName, Client: networkClient},
+		}
+		s.defaultCtrl = DefaultControllerName
+	}
+
+	s.server = server.NewMCPServer("unifi-network-mcp", "0.1.0",
+		server.WithToolHandlerMiddleware(s.logToolCall),
+		server.WithToolHandlerMiddleware(s.authorizeToolCall),
+		server.WithToolHandlerMiddleware(s.requireApproval),
+		server.WithToolFilter(s.filterAuthorizedTools),
+	)
+
 	s.registerTools()
 	return s
 }
 
-// resolveSiteID resolves a site identifier (name or ID) to the site external ID (UUID) for API v1 calls.
-// If siteID is empty or "default", it returns the first site's external ID.
-// Otherwise, it tries to find a site by name or ID and returns its external ID.
-func (s *Server) resolveSiteID(ctx context.Context, siteID string) (string, error) {
-	// Fetch sites to get the correct external ID
-	sites, err := s.networkClient.GetSites(ctx)
-	if err != nil {
-		return "", err
+// callerLogger returns a logger annotated with the identity of the caller in ctx
+func (s *Server) callerLogger(ctx context.Context) *logrus.Entry {
+	if id, ok := IdentityFromContext(ctx); ok {
+		return s.logger.WithFields(logrus.Fields{
+			"caller":      id.Subject,
+			"auth_method": id.Method,
+		})
+	}
+	return s.logger
+}
+
+// logToolCall records every tool invocation together with the caller identity
+func (s *Server) logToolCall(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.callerLogger(ctx).WithField("tool", request.Params.Name).Info("Tool call")
+
+		ctx, err := s.selectController(ctx, request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if noCache, _ := request.GetArguments()["no_cache"].(bool); noCache {
+			ctx = unifi.WithoutCache(ctx)
+		}
+		ctx, stats := unifi.WithRetryStats(withToolName(ctx, request.Params.Name))
+		result, err := next(ctx, request)
+		if details := errorDetails(result); details["kind"] == ErrorKindSiteNotFound {
+			// The site was deleted or renamed since the site list was cached
+			s.siteDirectory(ctx).invalidate()
+		}
+		if retries := stats.Retries(); retries > 0 {
+			s.callerLogger(ctx).WithFields(logrus.Fields{
+				"tool":    request.Params.Name,
+				"retries": retries,
+			}).Info("Tool call needed retries")
+			addRetries(result, retries)
+		}
+		return result, err
 	}
+}
+
+// noCacheProperty is added to the input schema of every read tool that talks to a controller
+var noCacheProperty = map[string]any{
+	"type":        "boolean",
+	"description": "Skip cached controller responses and fetch fresh data (optional, default false)",
+}
 
-	if len(sites) == 0 {
-		return "", fmt.Errorf("no sites available")
+// addRetries reports how many controller requests were retried in a tool result.
+// JSON object results gain a retries field; other results carry it in _meta.
+func addRetries(result *mcp.CallToolResult, retries int) {
+	if result == nil {
+		return
+	}
+	if data, ok := result.StructuredContent.(map[string]interface{}); ok && len(result.Content) == 1 {
+		if _, isText := result.Content[0].(mcp.TextContent); isText {
+			data["retries"] = retries
+			if text, err := json.Marshal(data); err == nil {
+				result.Content[0] = mcp.NewTextContent(string(text))
+				return
+			}
+		}
 	}
 
-	// If empty or "default", return the first site's external ID (UUID)
-	if siteID == "" || siteID == "default" {
-		return sites[0].ExternalID, nil
+	if result.Meta == nil {
+		result.Meta = &mcp.Meta{}
+	}
+	if result.Meta.AdditionalFields == nil {
+		result.Meta.AdditionalFields = map[string]any{}
 	}
+	result.Meta.AdditionalFields["retries"] = retries
+}
 
-	// Try to find by name first
-	for _, site := range sites {
-		if site.Name == siteID {
-			return site.ExternalID, nil
+// authorizeToolCall rejects tool calls that none of the caller's roles permit.
+// Calls without an identity (stdio, or HTTP without authentication) are not restricted.
+func (s *Server) authorizeToolCall(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, ok := IdentityFromContext(ctx)
+		if s.authz == nil || !ok {
+			return next(ctx, request)
+		}
+
+		if !s.authz.CanUseTool(id, request.Params.Name) {
+			s.callerLogger(ctx).WithField("tool", request.Params.Name).Warn("Tool call denied")
+			return mcp.NewToolResultError(fmt.Sprintf("access denied: %q (roles %v) is not permitted to call %s",
+				id.Subject, s.authz.RolesFor(id), request.Params.Name)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// filterAuthorizedTools hides tools the caller may not use from tools/list
+func (s *Server) filterAuthorizedTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	id, ok := IdentityFromContext(ctx)
+	if s.authz == nil || !ok {
+		return tools
+	}
+
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if s.authz.CanUseTool(id, tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// canUseSite reports whether the caller's roles permit the site. Calls without an
+// identity are not restricted.
+func (s *Server) canUseSite(ctx context.Context, site unifi.NetworkSite) bool {
+	id, ok := IdentityFromContext(ctx)
+	return s.authz == nil || !ok || s.authz.CanUseSite(id, site)
+}
+
+// authorizeSite returns an error when the caller's roles do not permit the site
+func (s *Server) authorizeSite(ctx context.Context, site unifi.NetworkSite) error {
+	if !s.canUseSite(ctx, site) {
+		id, _ := IdentityFromContext(ctx)
+		name := site.Name
+		if name == "" {
+			name = site.ExternalID
 		}
+		s.callerLogger(ctx).WithField("site", name).Warn("Site access denied")
+		return fmt.Errorf("access denied: %q (roles %v) is not permitted to use site %s", id.Subject, s.authz.RolesFor(id), name)
 	}
+	return nil
+}
 
-	// Try to find by ID (either _id or external_id)
+// authorizeAllSites returns an error unless the caller's roles permit every site
+// of the controller. It guards data that belongs to no site yet, such as devices
+// pending adoption, which a caller restricted to some sites must not see.
+func (s *Server) authorizeAllSites(ctx context.Context) error {
+	if _, ok := IdentityFromContext(ctx); s.authz == nil || !ok {
+		return nil
+	}
+	sites, _, err := s.siteDirectory(ctx).list(ctx, s.client(ctx), s.siteTTL, false)
+	if err != nil {
+		return err
+	}
 	for _, site := range sites {
-		if site.ID == siteID || site.ExternalID == siteID {
-			return site.ExternalID, nil
+		if err := s.authorizeSite(ctx, site); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// If not found, assume it's already a site external ID (UUID) and return it
-	return siteID, nil
+// resolveSiteID resolves a site identifier to the site external ID (UUID) for API v1 calls.
+// If siteID is empty or "default", it returns the first site's external ID.
+// Otherwise, it finds the site by ID, or by name or description ignoring case.
+// Site lists are cached per controller for the site cache TTL.
+func (s *Server) resolveSiteID(ctx context.Context, siteID string) (string, error) {
+	site, err := s.findSite(ctx, siteID)
+	if err != nil {
+		return "", err
+	}
+	return site.ExternalID, nil
+}
+
+// resolveSite resolves a site identifier like resolveSiteID but returns the whole
+// site. Integration API calls take its ExternalID, while legacy /api/s/{site}
+// calls take its short Name, e.g. "default".
+func (s *Server) resolveSite(ctx context.Context, siteID string) (unifi.NetworkSite, error) {
+	return s.findSite(ctx, siteID)
 }
 
 func (s *Server) registerTools() {
 	tools := []server.ServerTool{}
 
-	// Helper to create tool definitions
-	addTool := func(name, desc string, handler server.ToolHandlerFunc, properties map[string]any) {
+	// Helpers to create tool definitions; write tools are those that change controller state
+	outputs := outputSchemas()
+	register := func(name, desc string, handler server.ToolHandlerFunc, properties map[string]any, readOnly bool) {
+		if !localTools[name] {
+			properties["controller"] = controllerProperty
+			if readOnly {
+				properties["no_cache"] = noCacheProperty
+			}
+		}
 		tools = append(tools, server.ServerTool{
 			Tool: mcp.Tool{
 				Name:        name,
@@ -82,19 +302,32 @@ func (s *Server) registerTools() {
 					Type:       "object",
 					Properties: properties,
 				},
+				OutputSchema: outputs[name],
+				Annotations: mcp.ToolAnnotation{
+					ReadOnlyHint:    mcp.ToBoolPtr(readOnly),
+					DestructiveHint: mcp.ToBoolPtr(!readOnly),
+				},
 			},
 			Handler: handler,
 		})
 	}
+	addTool := func(name, desc string, handler server.ToolHandlerFunc, properties map[string]any) {
+		register(name, desc, handler, properties, true)
+	}
+	addWriteTool := func(name, desc string, handler server.ToolHandlerFunc, properties map[string]any) {
+		register(name, desc, handler, properties, false)
+	}
+
+	addTool("list_controllers", "List the UniFi controllers this server can reach; pass a name as the controller argument of other tools", s.listControllers, map[string]any{})
 
 	// Network Management
 	addTool("get_network_sites", "Get all sites from Unifi Network", s.getNetworkSites, map[string]any{})
 	addTool("get_network_devices", "Get all devices from Unifi Network", s.getNetworkDevices, map[string]any{
 		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
 	})
-	addTool("get_device_detailed", "Get detailed information about a specific device", s.getDeviceDetailed, map[string]any{
+	addTool("get_device_detailed", "Get the full record of a device: port and radio tables, PoE, uplink, system stats, temperatures and management network, with a compact summary", s.getDeviceDetailed, map[string]any{
 		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
-		"device_id": map[string]any{"type": "string", "description": "Device ID (required)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID or MAC address (required)"},
 	})
 	addTool("get_device_stats", "Get statistics for a specific device", s.getDeviceStats, map[string]any{
 		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
@@ -188,59 +421,321 @@ func (s *Server) registerTools() {
 	addTool("get_dpi_applications", "Get DPI applications list", s.getDPIApplications, map[string]any{})
 
 	// Update handlers
-	addTool("patch_wifi_network", "Update WiFi network settings", s.patchWiFiNetwork, map[string]any{
+	addWriteTool("patch_wifi_network", "Update WiFi network settings", s.patchWiFiNetwork, map[string]any{
 		"site_id":    map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
 		"network_id": map[string]any{"type": "string", "description": "Network ID (required)"},
-		"settings":   map[string]any{"type": "object", "description": "Settings to update (required)"},
+		"settings":   wifiNetworkPayload.settingsProperty(),
+		"dry_run":    dryRunProperty,
 	})
-	addTool("patch_firewall_zone", "Update firewall zone", s.patchFirewallZone, map[string]any{
+	addWriteTool("patch_firewall_zone", "Update firewall zone", s.patchFirewallZone, map[string]any{
 		"site_id":  map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
 		"zone_id":  map[string]any{"type": "string", "description": "Zone ID (required)"},
-		"settings": map[string]any{"type": "object", "description": "Settings to update (required)"},
+		"settings": firewallZonePayload.settingsProperty(),
+		"dry_run":  dryRunProperty,
 	})
-	addTool("patch_acl_rule", "Update ACL rule", s.patchACLRule, map[string]any{
+	addWriteTool("patch_acl_rule", "Update ACL rule", s.patchACLRule, map[string]any{
 		"site_id":  map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
 		"rule_id":  map[string]any{"type": "string", "description": "Rule ID (required)"},
-		"settings": map[string]any{"type": "object", "description": "Settings to update (required)"},
+		"settings": aclRulePayload.settingsProperty(),
+		"dry_run":  dryRunProperty,
 	})
-	addTool("patch_hotspot_voucher", "Update hotspot voucher", s.patchHotspotVoucher, map[string]any{
+	addWriteTool("patch_hotspot_voucher", "Update hotspot voucher", s.patchHotspotVoucher, map[string]any{
 		"site_id":    map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
 		"voucher_id": map[string]any{"type": "string", "description": "Voucher ID (required)"},
-		"settings":   map[string]any{"type": "object", "description": "Settings to update (required)"},
+		"settings":   hotspotVoucherPayload.settingsProperty(),
+		"dry_run":    dryRunProperty,
 	})
-	addTool("patch_traffic_rule", "Update traffic rule", s.patchTrafficRule, map[string]any{
+	addWriteTool("patch_traffic_rule", "Update traffic rule", s.patchTrafficRule, map[string]any{
 		"site_id":  map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
 		"rule_id":  map[string]any{"type": "string", "description": "Rule ID (required)"},
-		"settings": map[string]any{"type": "object", "description": "Settings to update (required)"},
+		"settings": trafficRulePayload.settingsProperty(),
+		"dry_run":  dryRunProperty,
 	})
 
 	// Create handlers
-	addTool("create_wifi_network", "Create a new WiFi network", s.createWiFiNetwork, map[string]any{
+	addWriteTool("create_wifi_network", "Create a new WiFi network", s.createWiFiNetwork, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"config":  wifiNetworkPayload.configProperty("WiFi network configuration"),
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("create_firewall_zone", "Create a new firewall zone", s.createFirewallZone, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"config":  firewallZonePayload.configProperty("Firewall zone configuration"),
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("create_acl_rule", "Create a new ACL rule", s.createACLRule, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"config":  aclRulePayload.configProperty("ACL rule configuration"),
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("create_hotspot_voucher", "Create a new hotspot voucher", s.createHotspotVoucher, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"config":  hotspotVoucherPayload.configProperty("Voucher configuration"),
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("create_traffic_rule", "Create a new traffic rule", s.createTrafficRule, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"config":  trafficRulePayload.configProperty("Traffic rule configuration"),
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("create_vpn_tunnel", "Create a new VPN tunnel", s.createVPNTunnel, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"config":  vpnTunnelPayload.configProperty("VPN tunnel configuration"),
+		"dry_run": dryRunProperty,
+	})
+
+	addWriteTool("delete_wifi_network", "Delete a WiFi network (requires confirm: true)", s.deleteWiFiNetwork, map[string]any{
+		"site_id":    map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"network_id": map[string]any{"type": "string", "description": "Network ID (required)"},
+		"confirm":    confirmProperty,
+		"dry_run":    dryRunProperty,
+	})
+	addWriteTool("delete_firewall_zone", "Delete a firewall zone (requires confirm: true)", s.deleteFirewallZone, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"zone_id": map[string]any{"type": "string", "description": "Zone ID (required)"},
+		"confirm": confirmProperty,
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("delete_acl_rule", "Delete an ACL rule (requires confirm: true)", s.deleteACLRule, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"rule_id": map[string]any{"type": "string", "description": "Rule ID (required)"},
+		"confirm": confirmProperty,
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("delete_hotspot_voucher", "Delete a hotspot voucher (requires confirm: true)", s.deleteHotspotVoucher, map[string]any{
+		"site_id":    map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"voucher_id": map[string]any{"type": "string", "description": "Voucher ID (required)"},
+		"confirm":    confirmProperty,
+		"dry_run":    dryRunProperty,
+	})
+	addWriteTool("delete_traffic_rule", "Delete a traffic rule (requires confirm: true)", s.deleteTrafficRule, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"rule_id": map[string]any{"type": "string", "description": "Rule ID (required)"},
+		"confirm": confirmProperty,
+		"dry_run": dryRunProperty,
+	})
+	addWriteTool("delete_vpn_tunnel", "Delete a VPN tunnel (requires confirm: true)", s.deleteVPNTunnel, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"tunnel_id": map[string]any{"type": "string", "description": "Tunnel ID (required)"},
+		"confirm":   confirmProperty,
+		"dry_run":   dryRunProperty,
+	})
+
+	// Device actions
+	addWriteTool("restart_device", "Restart (reboot) an adopted device", s.restartDevice, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID (required)"},
+		"dry_run":   dryRunProperty,
+	})
+	addWriteTool("locate_device", "Start or stop blinking the LED of a device so it can be found on site", s.locateDevice, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID (required)"},
+		"enabled":   map[string]any{"type": "boolean", "description": "true starts blinking, false stops it (optional, default true)"},
+	})
+	addWriteTool("force_provision_device", "Push the current configuration to a device again", s.forceProvisionDevice, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID (required)"},
+	})
+	addWriteTool("adopt_device", "Adopt a device pending adoption into a site", s.adoptDevice, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"mac":     map[string]any{"type": "string", "description": "MAC address of the pending device, from get_pending_devices (required)"},
+	})
+	addWriteTool("forget_device", "Remove a device from a site (requires confirm: true)", s.forgetDevice, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID (required)"},
+		"confirm":   map[string]any{"type": "boolean", "description": "Must be true to forget the device; it has to be adopted again afterwards (required unless dry_run is set)"},
+		"dry_run":   dryRunProperty,
+	})
+
+	// Firmware
+	addTool("get_available_firmware", "Get the firmware releases the controller offers per device model", s.getAvailableFirmware, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"model":   map[string]any{"type": "string", "description": "Only releases for this model code, e.g. U7PG2 (optional)"},
+	})
+	addTool("get_outdated_devices", "Get the devices of a site whose firmware is behind the newest available release", s.getOutdatedDevices, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+	})
+	addWriteTool("upgrade_device_firmware", "Upgrade the firmware of a device, or of every device with a tag, all at once", s.upgradeDeviceFirmware, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID or MAC address (required unless tag is set)"},
+		"tag":       map[string]any{"type": "string", "description": "Device tag ID or name from get_device_tags (required unless device_id is set)"},
+	})
+	addWriteTool("rollout_firmware", "Upgrade firmware in batches, waiting for each batch to reconnect and halting on the first failure", s.rolloutFirmware, map[string]any{
+		"site_id":       map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_ids":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Device IDs or MAC addresses, upgraded in this order (required unless tag is set)"},
+		"tag":           map[string]any{"type": "string", "description": "Device tag ID or name from get_device_tags (required unless device_ids is set)"},
+		"batch_size":    map[string]any{"type": "integer", "minimum": 1, "description": "Devices upgraded at the same time (optional, default 1)"},
+		"batch_timeout": map[string]any{"type": "string", "description": "How long to wait for a batch to reconnect, e.g. 15m (optional, default 10m)"},
+	})
+
+	// Switch ports
+	addTool("get_switch_ports", "Get the port table of a switch with link, PoE and profile state, and its port overrides", s.getSwitchPorts, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID or MAC address of the switch (required)"},
+	})
+	addWriteTool("power_cycle_port", "Briefly cut PoE power to a switch port to restart the device it powers", s.powerCyclePort, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id": map[string]any{"type": "string", "description": "Device ID or MAC address of the switch (required)"},
+		"port_idx":  map[string]any{"type": "integer", "minimum": 1, "description": "Port number (required)"},
+		"dry_run":   dryRunProperty,
+	})
+	addWriteTool("configure_switch_port", "Enable or disable a switch port, or set its port profile, native network, name or PoE mode through a port override", s.configureSwitchPort, map[string]any{
+		"site_id":           map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"device_id":         map[string]any{"type": "string", "description": "Device ID or MAC address of the switch (required)"},
+		"port_idx":          map[string]any{"type": "integer", "minimum": 1, "description": "Port number (required)"},
+		"enabled":           map[string]any{"type": "boolean", "description": "false disables the port, true lets it carry its profile's networks again (optional)"},
+		"port_profile_id":   map[string]any{"type": "string", "description": "Port profile ID from get_port_profiles (optional)"},
+		"native_network_id": map[string]any{"type": "string", "description": "ID of the network (VLAN) to carry untagged (optional)"},
+		"name":              map[string]any{"type": "string", "description": "Port name (optional)"},
+		"poe_mode":          map[string]any{"type": "string", "enum": poeModes, "description": "PoE mode (optional)"},
+		"dry_run":           dryRunProperty,
+	})
+	addTool("get_port_profiles", "Get the switch port profiles of a site", s.getPortProfiles, map[string]any{
 		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
-		"config":  map[string]any{"type": "object", "description": "WiFi network configuration (required)"},
 	})
-	addTool("create_firewall_zone", "Create a new firewall zone", s.createFirewallZone, map[string]any{
+	addWriteTool("create_port_profile", "Create a new switch port profile", s.createPortProfile, map[string]any{
 		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
-		"config":  map[string]any{"type": "object", "description": "Firewall zone configuration (required)"},
+		"config":  portProfilePayload.configProperty("Port profile configuration"),
+		"dry_run": dryRunProperty,
 	})
-	addTool("create_acl_rule", "Create a new ACL rule", s.createACLRule, map[string]any{
+	addWriteTool("patch_port_profile", "Update a switch port profile", s.patchPortProfile, map[string]any{
+		"site_id":    map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"profile_id": map[string]any{"type": "string", "description": "Port profile ID (required)"},
+		"settings":   portProfilePayload.settingsProperty(),
+		"dry_run":    dryRunProperty,
+	})
+	addWriteTool("delete_port_profile", "Delete a switch port profile (requires confirm: true)", s.deletePortProfile, map[string]any{
+		"site_id":    map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"profile_id": map[string]any{"type": "string", "description": "Port profile ID (required)"},
+		"confirm":    confirmProperty,
+		"dry_run":    dryRunProperty,
+	})
+
+	// Client actions
+	addWriteTool("block_client", "Block a client from every network of the site", s.blockClient, map[string]any{
 		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
-		"config":  map[string]any{"type": "object", "description": "ACL rule configuration (required)"},
+		"mac":     macProperty,
 	})
-	addTool("create_hotspot_voucher", "Create a new hotspot voucher", s.createHotspotVoucher, map[string]any{
+	addWriteTool("unblock_client", "Lift the block of a client", s.unblockClient, map[string]any{
 		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
-		"config":  map[string]any{"type": "object", "description": "Voucher configuration (required)"},
+		"mac":     macProperty,
 	})
-	addTool("create_traffic_rule", "Create a new traffic rule", s.createTrafficRule, map[string]any{
+	addWriteTool("kick_client", "Disconnect a client, forcing it to reconnect", s.kickClient, map[string]any{
 		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
-		"config":  map[string]any{"type": "object", "description": "Traffic rule configuration (required)"},
+		"mac":     macProperty,
+	})
+	addWriteTool("forget_client", "Delete the history, name and settings kept for a client (requires confirm: true)", s.forgetClient, map[string]any{
+		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"mac":     macProperty,
+		"confirm": map[string]any{"type": "boolean", "description": "Must be true to forget the client (required)"},
+	})
+	addWriteTool("authorize_guest", "Authorize a hotspot guest through the guest portal for a number of minutes, optionally with bandwidth and data caps", s.authorizeGuest, map[string]any{
+		"site_id":   map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
+		"mac":       macProperty,
+		"minutes":   map[string]any{"type": "integer", "minimum": 1, "description": "How long the guest is authorized, in minutes (required)"},
+		"up_kbps":   map[string]any{"type": "integer", "minimum": 0, "description": "Upload limit in Kbps (optional)"},
+		"down_kbps": map[string]any{"type": "integer", "minimum": 0, "description": "Download limit in Kbps (optional)"},
+		"bytes_mb":  map[string]any{"type": "integer", "minimum": 0, "description": "Data transfer quota in MB (optional)"},
+		"ap_mac":    map[string]any{"type": "string", "description": "MAC address of the access point the guest is connected to (optional)"},
 	})
-	addTool("create_vpn_tunnel", "Create a new VPN tunnel", s.createVPNTunnel, map[string]any{
+	addWriteTool("unauthorize_guest", "Revoke the guest portal authorization of a hotspot guest", s.unauthorizeGuest, map[string]any{
 		"site_id": map[string]any{"type": "string", "description": "Site ID (optional, defaults to first site)"},
-		"config":  map[string]any{"type": "object", "description": "VPN tunnel configuration (required)"},
+		"mac":     macProperty,
 	})
 
-	s.server.AddTools(tools...)
+	// Approval workflow
+	if s.approvals != nil {
+		addTool("list_pending_changes", "List changes queued for approval", s.listPendingChanges, map[string]any{
+			"status": map[string]any{"type": "string", "enum": []string{ChangePending, ChangeApplied, ChangeFailed, ChangeRejected, "all"}, "description": "Filter by status (optional, default pending)"},
+		})
+		addWriteTool("approve_change", "Approve and apply a queued change (must be approved by someone other than the requester)", s.approveChange, map[string]any{
+			"change_id": map[string]any{"type": "string", "description": "Change ID (required)"},
+		})
+		addWriteTool("reject_change", "Reject a queued change without applying it", s.rejectChange, map[string]any{
+			"change_id": map[string]any{"type": "string", "description": "Change ID (required)"},
+			"reason":    map[string]any{"type": "string", "description": "Reason for rejection (optional)"},
+		})
+	}
+
+	// Audit log
+	if s.audit != nil {
+		addTool("get_audit_log", "Get the audit log of write requests sent to the controller, newest first", s.getAuditLog, map[string]any{
+			"since":  map[string]any{"type": "string", "description": "Only entries at or after this RFC3339 time (optional)"},
+			"until":  map[string]any{"type": "string", "description": "Only entries at or before this RFC3339 time (optional)"},
+			"tool":   map[string]any{"type": "string", "description": "Only entries from tools matching this glob pattern (optional)"},
+			"caller": map[string]any{"type": "string", "description": "Only entries from this caller (optional)"},
+			"limit":  map[string]any{"type": "integer", "description": "Maximum number of entries (optional, default 50)"},
+		})
+	}
+
+	s.server.AddTools(s.exposedTools(tools)...)
+}
+
+// isWriteTool reports whether a tool changes controller state
+func isWriteTool(tool mcp.Tool) bool {
+	return tool.Annotations.ReadOnlyHint == nil || !*tool.Annotations.ReadOnlyHint
+}
+
+// integrationTools only work through the integration API, which needs an API key.
+// rollout_firmware watches devices reconnect through it.
+var integrationTools = map[string]bool{
+	"get_network_devices":           true,
+	"get_network_clients":           true,
+	"get_wifi_networks":             true,
+	"get_site_health":               true,
+	"get_network_info":              true,
+	"get_device_stats":              true,
+	"get_wifi_broadcasts":           true,
+	"get_firewall_zones":            true,
+	"get_acl_rules":                 true,
+	"get_hotspot_vouchers":          true,
+	"get_pending_devices":           true,
+	"get_dpi_categories":            true,
+	"get_client_detailed":           true,
+	"get_vpn_servers":               true,
+	"check_network_endpoint_health": true,
+	"rollout_firmware":              true,
+}
+
+// integrationAPI reports whether any controller has an API key for the integration API
+func (s *Server) integrationAPI() bool {
+	for _, c := range s.controllers {
+		if c.Client.IntegrationAPI() {
+			return true
+		}
+	}
+	return false
+}
+
+// exposedTools applies read-only mode, session-only logins and the allow/deny
+// patterns to the registered tools
+func (s *Server) exposedTools(tools []server.ServerTool) []server.ServerTool {
+	integrationAPI := s.integrationAPI()
+	exposed := make([]server.ServerTool, 0, len(tools))
+	for _, tool := range tools {
+		name := tool.Tool.Name
+		switch {
+		case s.readOnly && isWriteTool(tool.Tool):
+			s.logger.WithField("tool", name).Debug("Tool disabled by read-only mode")
+		case !integrationAPI && integrationTools[name]:
+			s.logger.WithField("tool", name).Debug("Tool needs an API key, which no controller has")
+		case len(s.toolsAllow) > 0 && !matchAny(s.toolsAllow, name):
+			s.logger.WithField("tool", name).Debug("Tool not in allow list")
+		case matchAny(s.toolsDeny, name):
+			s.logger.WithField("tool", name).Debug("Tool in deny list")
+		default:
+			exposed = append(exposed, tool)
+			s.exposed[name] = tool.Tool
+			s.toolHandlers[name] = tool.Handler
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"exposed":    len(exposed),
+		"registered": len(tools),
+		"read_only":  s.readOnly,
+	}).Info("Registered MCP tools")
+	return exposed
 }
 
 // GET Handlers
@@ -248,13 +743,22 @@ func (s *Server) registerTools() {
 func (s *Server) getNetworkSites(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_network_sites")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	sites, err := s.networkClient.GetSites(ctx)
+	// Listing sites always fetches them, which also refreshes the site cache
+	allSites, _, err := s.siteDirectory(ctx).list(ctx, s.client(ctx), s.siteTTL, true)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get sites", err), nil
+		return toolResultError("Failed to get sites", err), nil
+	}
+
+	// Only list the sites the caller is permitted to use
+	sites := make([]unifi.NetworkSite, 0, len(allSites))
+	for _, site := range allSites {
+		if s.canUseSite(ctx, site) {
+			sites = append(sites, site)
+		}
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -268,18 +772,18 @@ func (s *Server) getNetworkDevices(ctx context.Context, request mcp.CallToolRequ
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	devices, err := s.networkClient.GetDevices(ctx, resolvedSiteID)
+	devices, err := s.client(ctx).GetDevices(ctx, resolvedSiteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get devices", err), nil
+		return toolResultError("Failed to get devices", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -299,23 +803,24 @@ func (s *Server) getDeviceDetailed(ctx context.Context, request mcp.CallToolRequ
 		return mcp.NewToolResultError("device_id is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	device, err := s.networkClient.GetDeviceDetailed(ctx, resolvedSiteID, deviceID)
+	device, err := s.client(ctx).GetDeviceDetailed(ctx, site, deviceID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get device details", err), nil
+		return toolResultError("Failed to get device details", err), nil
 	}
 
 	result := map[string]interface{}{
 		"device":    device,
-		"site_id":   resolvedSiteID,
+		"summary":   device.Summary(),
+		"site_id":   site.ExternalID,
 		"device_id": deviceID,
 	}
 
@@ -332,18 +837,18 @@ func (s *Server) getDeviceStats(ctx context.Context, request mcp.CallToolRequest
 		return mcp.NewToolResultError("device_id is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	stats, err := s.networkClient.GetDeviceStats(ctx, resolvedSiteID, deviceID)
+	stats, err := s.client(ctx).GetDeviceStats(ctx, resolvedSiteID, deviceID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get device stats", err), nil
+		return toolResultError("Failed to get device stats", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -356,13 +861,13 @@ func (s *Server) getDeviceStats(ctx context.Context, request mcp.CallToolRequest
 func (s *Server) getNetworkInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_network_info")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	info, err := s.networkClient.GetInfo(ctx)
+	info, err := s.client(ctx).GetInfo(ctx)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get network info", err), nil
+		return toolResultError("Failed to get network info", err), nil
 	}
 
 	return mcp.NewToolResultJSON(info)
@@ -371,13 +876,18 @@ func (s *Server) getNetworkInfo(ctx context.Context, request mcp.CallToolRequest
 func (s *Server) getPendingDevices(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_pending_devices")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	// Pending devices are not in a site yet, so only callers permitted every site see them
+	if err := s.authorizeAllSites(ctx); err != nil {
+		return toolResultError("Failed to get pending devices", err), nil
 	}
 
-	devices, err := s.networkClient.GetPendingDevices(ctx)
+	devices, err := s.client(ctx).GetPendingDevices(ctx)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get pending devices", err), nil
+		return toolResultError("Failed to get pending devices", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -391,18 +901,18 @@ func (s *Server) getWiFiNetworks(ctx context.Context, request mcp.CallToolReques
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	networks, err := s.networkClient.GetWiFiNetworks(ctx, resolvedSiteID)
+	networks, err := s.client(ctx).GetWiFiNetworks(ctx, resolvedSiteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get wifi networks", err), nil
+		return toolResultError("Failed to get wifi networks", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -417,18 +927,18 @@ func (s *Server) getWiFiBroadcasts(ctx context.Context, request mcp.CallToolRequ
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	broadcasts, err := s.networkClient.GetWiFiBroadcasts(ctx, resolvedSiteID)
+	broadcasts, err := s.client(ctx).GetWiFiBroadcasts(ctx, resolvedSiteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get wifi broadcasts", err), nil
+		return toolResultError("Failed to get wifi broadcasts", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -445,18 +955,18 @@ func (s *Server) getNetworkClients(ctx context.Context, request mcp.CallToolRequ
 	limit := request.GetInt("limit", 25)
 	offset := request.GetInt("offset", 0)
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	clients, err := s.networkClient.GetClients(ctx, resolvedSiteID, limit, offset)
+	clients, err := s.client(ctx).GetClients(ctx, resolvedSiteID, limit, offset)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get network clients", err), nil
+		return toolResultError("Failed to get network clients", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -478,18 +988,18 @@ func (s *Server) getClientDetailed(ctx context.Context, request mcp.CallToolRequ
 		return mcp.NewToolResultError("mac is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	client, err := s.networkClient.GetClientDetailed(ctx, resolvedSiteID, mac)
+	client, err := s.client(ctx).GetClientDetailed(ctx, resolvedSiteID, mac)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get client details", err), nil
+		return toolResultError("Failed to get client details", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -504,23 +1014,23 @@ func (s *Server) getClientStats(ctx context.Context, request mcp.CallToolRequest
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	stats, err := s.networkClient.GetClientStats(ctx, resolvedSiteID)
+	stats, err := s.client(ctx).GetClientStats(ctx, site.Name)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get client stats", err), nil
+		return toolResultError("Failed to get client stats", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"stats":   stats,
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	})
 }
 
@@ -529,18 +1039,18 @@ func (s *Server) getFirewallZones(ctx context.Context, request mcp.CallToolReque
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	zones, err := s.networkClient.GetFirewallZones(ctx, resolvedSiteID)
+	zones, err := s.client(ctx).GetFirewallZones(ctx, resolvedSiteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get firewall zones", err), nil
+		return toolResultError("Failed to get firewall zones", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -555,18 +1065,18 @@ func (s *Server) getACLRules(ctx context.Context, request mcp.CallToolRequest) (
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	rules, err := s.networkClient.GetACLRules(ctx, resolvedSiteID)
+	rules, err := s.client(ctx).GetACLRules(ctx, resolvedSiteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get acl rules", err), nil
+		return toolResultError("Failed to get acl rules", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -581,18 +1091,18 @@ func (s *Server) getHotspotVouchers(ctx context.Context, request mcp.CallToolReq
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	vouchers, err := s.networkClient.GetHotspotVouchers(ctx, resolvedSiteID)
+	vouchers, err := s.client(ctx).GetHotspotVouchers(ctx, resolvedSiteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get hotspot vouchers", err), nil
+		return toolResultError("Failed to get hotspot vouchers", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -607,24 +1117,24 @@ func (s *Server) getTrafficRules(ctx context.Context, request mcp.CallToolReques
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	rules, err := s.networkClient.GetTrafficRules(ctx, resolvedSiteID)
+	rules, err := s.client(ctx).GetTrafficRules(ctx, site.Name)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get traffic rules", err), nil
+		return toolResultError("Failed to get traffic rules", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"rules":   rules,
 		"count":   len(rules),
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	})
 }
 
@@ -633,18 +1143,18 @@ func (s *Server) getVPNServers(ctx context.Context, request mcp.CallToolRequest)
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	servers, err := s.networkClient.GetVPNServers(ctx, resolvedSiteID)
+	servers, err := s.client(ctx).GetVPNServers(ctx, resolvedSiteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get vpn servers", err), nil
+		return toolResultError("Failed to get vpn servers", err), nil
 	}
 
 	result := map[string]interface{}{
@@ -659,13 +1169,13 @@ func (s *Server) getVPNServers(ctx context.Context, request mcp.CallToolRequest)
 func (s *Server) getDPICategories(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_dpi_categories")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	categories, err := s.networkClient.GetDPICategories(ctx)
+	categories, err := s.client(ctx).GetDPICategories(ctx)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get dpi categories", err), nil
+		return toolResultError("Failed to get dpi categories", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -677,13 +1187,13 @@ func (s *Server) getDPICategories(ctx context.Context, request mcp.CallToolReque
 func (s *Server) getDPIApps(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_dpi_apps")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	apps, err := s.networkClient.GetDPIApplications(ctx)
+	apps, err := s.client(ctx).GetDPIApplications(ctx)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get dpi apps", err), nil
+		return toolResultError("Failed to get dpi apps", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
@@ -704,28 +1214,35 @@ func (s *Server) patchWiFiNetwork(ctx context.Context, request mcp.CallToolReque
 	if !ok {
 		return mcp.NewToolResultError("settings must be an object"), nil
 	}
+	if err := wifiNetworkPayload.validate("settings", settings, false); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if networkID == "" {
 		return mcp.NewToolResultError("network_id is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planPatch(ctx, site, unifi.ResourceWiFiNetwork, networkID, settings)
 	}
 
-	result, err := s.networkClient.PatchWiFiNetwork(ctx, resolvedSiteID, networkID, settings)
+	result, err := s.client(ctx).PatchWiFiNetwork(ctx, site.Name, networkID, settings)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to update wifi network", err), nil
+		return toolResultError("Failed to update wifi network", err), nil
 	}
 
 	result["success"] = true
 	result["network_id"] = networkID
-	result["site_id"] = resolvedSiteID
+	result["site_id"] = site.ExternalID
 	return mcp.NewToolResultJSON(result)
 }
 
@@ -739,28 +1256,35 @@ func (s *Server) patchFirewallZone(ctx context.Context, request mcp.CallToolRequ
 	if !ok {
 		return mcp.NewToolResultError("settings must be an object"), nil
 	}
+	if err := firewallZonePayload.validate("settings", settings, false); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if zoneID == "" {
 		return mcp.NewToolResultError("zone_id is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	result, err := s.networkClient.PatchFirewallZone(ctx, resolvedSiteID, zoneID, settings)
+	if request.GetBool("dry_run", false) {
+		return s.planPatch(ctx, site, unifi.ResourceFirewallZone, zoneID, settings)
+	}
+
+	result, err := s.client(ctx).PatchFirewallZone(ctx, site.Name, zoneID, settings)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to update firewall zone", err), nil
+		return toolResultError("Failed to update firewall zone", err), nil
 	}
 
 	result["success"] = true
 	result["zone_id"] = zoneID
-	result["site_id"] = resolvedSiteID
+	result["site_id"] = site.ExternalID
 	return mcp.NewToolResultJSON(result)
 }
 
@@ -774,28 +1298,35 @@ func (s *Server) patchACLRule(ctx context.Context, request mcp.CallToolRequest)
 	if !ok {
 		return mcp.NewToolResultError("settings must be an object"), nil
 	}
+	if err := aclRulePayload.validate("settings", settings, false); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if ruleID == "" {
 		return mcp.NewToolResultError("rule_id is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planPatch(ctx, site, unifi.ResourceACLRule, ruleID, settings)
 	}
 
-	result, err := s.networkClient.PatchACLRule(ctx, resolvedSiteID, ruleID, settings)
+	result, err := s.client(ctx).PatchACLRule(ctx, site.Name, ruleID, settings)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to update acl rule", err), nil
+		return toolResultError("Failed to update acl rule", err), nil
 	}
 
 	result["success"] = true
 	result["rule_id"] = ruleID
-	result["site_id"] = resolvedSiteID
+	result["site_id"] = site.ExternalID
 	return mcp.NewToolResultJSON(result)
 }
 
@@ -809,28 +1340,35 @@ func (s *Server) patchHotspotVoucher(ctx context.Context, request mcp.CallToolRe
 	if !ok {
 		return mcp.NewToolResultError("settings must be an object"), nil
 	}
+	if err := hotspotVoucherPayload.validate("settings", settings, false); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if voucherID == "" {
 		return mcp.NewToolResultError("voucher_id is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planPatch(ctx, site, unifi.ResourceHotspotVoucher, voucherID, settings)
 	}
 
-	result, err := s.networkClient.PatchHotspotVoucher(ctx, resolvedSiteID, voucherID, settings)
+	result, err := s.client(ctx).PatchHotspotVoucher(ctx, site.Name, voucherID, settings)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to update hotspot voucher", err), nil
+		return toolResultError("Failed to update hotspot voucher", err), nil
 	}
 
 	result["success"] = true
 	result["voucher_id"] = voucherID
-	result["site_id"] = resolvedSiteID
+	result["site_id"] = site.ExternalID
 	return mcp.NewToolResultJSON(result)
 }
 
@@ -844,28 +1382,35 @@ func (s *Server) patchTrafficRule(ctx context.Context, request mcp.CallToolReque
 	if !ok {
 		return mcp.NewToolResultError("settings must be an object"), nil
 	}
+	if err := trafficRulePayload.validate("settings", settings, false); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if ruleID == "" {
 		return mcp.NewToolResultError("rule_id is required"), nil
 	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	result, err := s.networkClient.PatchTrafficRule(ctx, resolvedSiteID, ruleID, settings)
+	if request.GetBool("dry_run", false) {
+		return s.planPatch(ctx, site, unifi.ResourceTrafficRule, ruleID, settings)
+	}
+
+	result, err := s.client(ctx).PatchTrafficRule(ctx, site.Name, ruleID, settings)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to update traffic rule", err), nil
+		return toolResultError("Failed to update traffic rule", err), nil
 	}
 
 	result["success"] = true
 	result["rule_id"] = ruleID
-	result["site_id"] = resolvedSiteID
+	result["site_id"] = site.ExternalID
 	return mcp.NewToolResultJSON(result)
 }
 
@@ -880,25 +1425,32 @@ func (s *Server) createWiFiNetwork(ctx context.Context, request mcp.CallToolRequ
 	if !ok {
 		return mcp.NewToolResultError("config must be an object"), nil
 	}
+	if err := wifiNetworkPayload.validate("config", config, true); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	result, err := s.networkClient.CreateWiFiNetwork(ctx, resolvedSiteID, config)
+	if request.GetBool("dry_run", false) {
+		return s.planCreate(ctx, site, unifi.ResourceWiFiNetwork, config)
+	}
+
+	result, err := s.client(ctx).CreateWiFiNetwork(ctx, site.Name, config)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create wifi network", err), nil
+		return toolResultError("Failed to create wifi network", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"success": true,
 		"network": result,
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	})
 }
 
@@ -911,25 +1463,32 @@ func (s *Server) createFirewallZone(ctx context.Context, request mcp.CallToolReq
 	if !ok {
 		return mcp.NewToolResultError("config must be an object"), nil
 	}
+	if err := firewallZonePayload.validate("config", config, true); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	result, err := s.networkClient.CreateFirewallZone(ctx, resolvedSiteID, config)
+	if request.GetBool("dry_run", false) {
+		return s.planCreate(ctx, site, unifi.ResourceFirewallZone, config)
+	}
+
+	result, err := s.client(ctx).CreateFirewallZone(ctx, site.Name, config)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create firewall zone", err), nil
+		return toolResultError("Failed to create firewall zone", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"success": true,
 		"zone":    result,
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	})
 }
 
@@ -942,25 +1501,32 @@ func (s *Server) createACLRule(ctx context.Context, request mcp.CallToolRequest)
 	if !ok {
 		return mcp.NewToolResultError("config must be an object"), nil
 	}
+	if err := aclRulePayload.validate("config", config, true); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planCreate(ctx, site, unifi.ResourceACLRule, config)
 	}
 
-	result, err := s.networkClient.CreateACLRule(ctx, resolvedSiteID, config)
+	result, err := s.client(ctx).CreateACLRule(ctx, site.Name, config)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create acl rule", err), nil
+		return toolResultError("Failed to create acl rule", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"success": true,
 		"rule":    result,
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	})
 }
 
@@ -973,25 +1539,32 @@ func (s *Server) createHotspotVoucher(ctx context.Context, request mcp.CallToolR
 	if !ok {
 		return mcp.NewToolResultError("config must be an object"), nil
 	}
+	if err := hotspotVoucherPayload.validate("config", config, true); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planCreate(ctx, site, unifi.ResourceHotspotVoucher, config)
 	}
 
-	result, err := s.networkClient.CreateHotspotVoucher(ctx, resolvedSiteID, config)
+	result, err := s.client(ctx).CreateHotspotVoucher(ctx, site.Name, config)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create hotspot voucher", err), nil
+		return toolResultError("Failed to create hotspot voucher", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"success": true,
 		"voucher": result,
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	})
 }
 
@@ -1004,25 +1577,32 @@ func (s *Server) createTrafficRule(ctx context.Context, request mcp.CallToolRequ
 	if !ok {
 		return mcp.NewToolResultError("config must be an object"), nil
 	}
+	if err := trafficRulePayload.validate("config", config, true); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	result, err := s.networkClient.CreateTrafficRule(ctx, resolvedSiteID, config)
+	if request.GetBool("dry_run", false) {
+		return s.planCreate(ctx, site, unifi.ResourceTrafficRule, config)
+	}
+
+	result, err := s.client(ctx).CreateTrafficRule(ctx, site.Name, config)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create traffic rule", err), nil
+		return toolResultError("Failed to create traffic rule", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"success": true,
 		"rule":    result,
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	})
 }
 
@@ -1035,45 +1615,131 @@ func (s *Server) createVPNTunnel(ctx context.Context, request mcp.CallToolReques
 	if !ok {
 		return mcp.NewToolResultError("config must be an object"), nil
 	}
+	if err := vpnTunnelPayload.validate("config", config, true); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if request.GetBool("dry_run", false) {
+		return s.planCreate(ctx, site, unifi.ResourceVPNTunnel, config)
 	}
 
-	result, err := s.networkClient.CreateVPNTunnel(ctx, resolvedSiteID, config)
+	result, err := s.client(ctx).CreateVPNTunnel(ctx, site.Name, config)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to create vpn tunnel", err), nil
+		return toolResultError("Failed to create vpn tunnel", err), nil
 	}
 
 	return mcp.NewToolResultJSON(map[string]interface{}{
 		"success": true,
 		"tunnel":  result,
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
+	})
+}
+
+// DELETE Handlers
+
+// confirmProperty is the input schema entry that guards every delete tool
+var confirmProperty = map[string]any{
+	"type":        "boolean",
+	"description": "Must be true to delete; deletion cannot be undone (required unless dry_run is set)",
+}
+
+// deleteObject implements the delete tools: it validates the object ID and the
+// confirm flag, then plans or performs the deletion
+func (s *Server) deleteObject(ctx context.Context, request mcp.CallToolRequest, idParam string, resource unifi.Resource,
+	remove func(ctx context.Context, siteID, id string) error) (*mcp.CallToolResult, error) {
+	siteID := request.GetString("site_id", "")
+	id := request.GetString(idParam, "")
+	if id == "" {
+		return mcp.NewToolResultError(idParam + " is required"), nil
+	}
+
+	dryRun := request.GetBool("dry_run", false)
+	if !dryRun && !request.GetBool("confirm", false) {
+		return mcp.NewToolResultError("confirm must be true to delete; use dry_run to preview the deletion first"), nil
+	}
+
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
+	}
+
+	site, err := s.resolveSite(ctx, siteID)
+	if err != nil {
+		return toolResultError("Failed to resolve site ID", err), nil
+	}
+
+	if dryRun {
+		return s.planDelete(ctx, site, resource, id)
+	}
+
+	if err := remove(ctx, site.Name, id); err != nil {
+		object := strings.ReplaceAll(strings.TrimPrefix(request.Params.Name, "delete_"), "_", " ")
+		return toolResultError("Failed to delete "+object, err), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]interface{}{
+		"success": true,
+		"deleted": true,
+		idParam:   id,
+		"site_id": site.ExternalID,
 	})
 }
 
+func (s *Server) deleteWiFiNetwork(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: delete_wifi_network")
+	return s.deleteObject(ctx, request, "network_id", unifi.ResourceWiFiNetwork, s.client(ctx).DeleteWiFiNetwork)
+}
+
+func (s *Server) deleteFirewallZone(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: delete_firewall_zone")
+	return s.deleteObject(ctx, request, "zone_id", unifi.ResourceFirewallZone, s.client(ctx).DeleteFirewallZone)
+}
+
+func (s *Server) deleteACLRule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: delete_acl_rule")
+	return s.deleteObject(ctx, request, "rule_id", unifi.ResourceACLRule, s.client(ctx).DeleteACLRule)
+}
+
+func (s *Server) deleteHotspotVoucher(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: delete_hotspot_voucher")
+	return s.deleteObject(ctx, request, "voucher_id", unifi.ResourceHotspotVoucher, s.client(ctx).DeleteHotspotVoucher)
+}
+
+func (s *Server) deleteTrafficRule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: delete_traffic_rule")
+	return s.deleteObject(ctx, request, "rule_id", unifi.ResourceTrafficRule, s.client(ctx).DeleteTrafficRule)
+}
+
+func (s *Server) deleteVPNTunnel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.logger.Debug("Tool called: delete_vpn_tunnel")
+	return s.deleteObject(ctx, request, "tunnel_id", unifi.ResourceVPNTunnel, s.client(ctx).DeleteVPNTunnel)
+}
+
 func (s *Server) getWiFiNetworkDetailed(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_wifi_network_detailed")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
 	networkID := request.GetString("network_id", "")
 	if networkID == "" {
-		return mcp.NewToolResultErrorFromErr("Missing required parameter: network_id", nil), nil
+		return toolResultError("Missing required parameter: network_id", nil), nil
 	}
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	network, err := s.networkClient.GetWiFiNetworkDetailed(ctx, resolvedSiteID, networkID)
+	network, err := s.client(ctx).GetWiFiNetworkDetailed(ctx, site.Name, networkID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get network details", err), nil
+		return toolResultError("Failed to get network details", err), nil
 	}
 	return mcp.NewToolResultJSON(network)
 }
@@ -1083,22 +1749,22 @@ func (s *Server) getSiteHealth(ctx context.Context, request mcp.CallToolRequest)
 
 	siteID := request.GetString("site_id", "")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
 		s.logger.WithError(err).Error("Failed to authenticate with Network")
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	// Resolve site ID (if empty, uses first site)
 	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to resolve site ID")
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
 
-	health, err := s.networkClient.GetHealth(ctx, resolvedSiteID)
+	health, err := s.client(ctx).GetHealth(ctx, resolvedSiteID)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get health")
-		return mcp.NewToolResultErrorFromErr("Failed to get health", err), nil
+		return toolResultError("Failed to get health", err), nil
 	}
 
 	result := map[string]interface{}{
@@ -1112,15 +1778,15 @@ func (s *Server) getSiteHealth(ctx context.Context, request mcp.CallToolRequest)
 func (s *Server) checkNetworkEndpointHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: check_network_endpoint_health")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
 		s.logger.WithError(err).Error("Failed to authenticate with Network")
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+		return toolResultError("Authentication failed", err), nil
 	}
 
-	health, err := s.networkClient.CheckEndpointHealth(ctx)
+	health, err := s.client(ctx).CheckEndpointHealth(ctx)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to check Network endpoint health")
-		return mcp.NewToolResultErrorFromErr("Failed to check endpoint health", err), nil
+		return toolResultError("Failed to check endpoint health", err), nil
 	}
 
 	result := map[string]interface{}{
@@ -1134,9 +1800,9 @@ func (s *Server) checkNetworkEndpointHealth(ctx context.Context, request mcp.Cal
 func (s *Server) checkProtectEndpointHealth(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: check_protect_endpoint_health")
 
-	if err := s.networkClient.Authenticate(ctx); err != nil {
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
 		s.logger.WithError(err).Error("Failed to authenticate with Network")
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+		return toolResultError("Authentication failed", err), nil
 	}
 
 	result := map[string]interface{}{
@@ -1150,162 +1816,162 @@ func (s *Server) checkProtectEndpointHealth(ctx context.Context, request mcp.Cal
 
 func (s *Server) getFirewallZoneDetailed(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_firewall_zone_detailed")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
 	zoneID := request.GetString("firewall_zone_id", "")
 	if zoneID == "" {
-		return mcp.NewToolResultErrorFromErr("Missing required parameter: firewall_zone_id", nil), nil
+		return toolResultError("Missing required parameter: firewall_zone_id", nil), nil
 	}
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	zone, err := s.networkClient.GetFirewallZoneDetailed(ctx, resolvedSiteID, zoneID)
+	zone, err := s.client(ctx).GetFirewallZoneDetailed(ctx, site.Name, zoneID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get firewall zone details", err), nil
+		return toolResultError("Failed to get firewall zone details", err), nil
 	}
 	return mcp.NewToolResultJSON(zone)
 }
 
 func (s *Server) getACLRuleDetailed(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_acl_rule_detailed")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
 	ruleID := request.GetString("acl_rule_id", "")
 	if ruleID == "" {
-		return mcp.NewToolResultErrorFromErr("Missing required parameter: acl_rule_id", nil), nil
+		return toolResultError("Missing required parameter: acl_rule_id", nil), nil
 	}
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	rule, err := s.networkClient.GetACLRuleDetailed(ctx, resolvedSiteID, ruleID)
+	rule, err := s.client(ctx).GetACLRuleDetailed(ctx, site.Name, ruleID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get ACL rule details", err), nil
+		return toolResultError("Failed to get ACL rule details", err), nil
 	}
 	return mcp.NewToolResultJSON(rule)
 }
 
 func (s *Server) getHotspotVoucherDetailed(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_hotspot_voucher_detailed")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
 	voucherID := request.GetString("voucher_id", "")
 	if voucherID == "" {
-		return mcp.NewToolResultErrorFromErr("Missing required parameter: voucher_id", nil), nil
+		return toolResultError("Missing required parameter: voucher_id", nil), nil
 	}
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	voucher, err := s.networkClient.GetHotspotVoucherDetailed(ctx, resolvedSiteID, voucherID)
+	voucher, err := s.client(ctx).GetHotspotVoucherDetailed(ctx, site.Name, voucherID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get voucher details", err), nil
+		return toolResultError("Failed to get voucher details", err), nil
 	}
 	return mcp.NewToolResultJSON(voucher)
 }
 
 func (s *Server) getTrafficRuleDetailed(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_traffic_rule_detailed")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
 	ruleID := request.GetString("traffic_matching_list_id", "")
 	if ruleID == "" {
-		return mcp.NewToolResultErrorFromErr("Missing required parameter: traffic_matching_list_id", nil), nil
+		return toolResultError("Missing required parameter: traffic_matching_list_id", nil), nil
 	}
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	rule, err := s.networkClient.GetTrafficRuleDetailed(ctx, resolvedSiteID, ruleID)
+	rule, err := s.client(ctx).GetTrafficRuleDetailed(ctx, site.Name, ruleID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get traffic rule details", err), nil
+		return toolResultError("Failed to get traffic rule details", err), nil
 	}
 	return mcp.NewToolResultJSON(rule)
 }
 
 func (s *Server) getDeviceTags(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_device_tags")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	tags, err := s.networkClient.GetDeviceTags(ctx, resolvedSiteID)
+	tags, err := s.client(ctx).GetDeviceTags(ctx, site.Name)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get device tags", err), nil
+		return toolResultError("Failed to get device tags", err), nil
 	}
 	result := map[string]interface{}{
 		"tags":    tags,
 		"count":   len(tags),
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	}
 	return mcp.NewToolResultJSON(result)
 }
 
 func (s *Server) getWANConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_wan_config")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	wanConfig, err := s.networkClient.GetWANConfig(ctx, resolvedSiteID)
+	wanConfig, err := s.client(ctx).GetWANConfig(ctx, site.Name)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get WAN config", err), nil
+		return toolResultError("Failed to get WAN config", err), nil
 	}
 	result := map[string]interface{}{
 		"wans":    wanConfig,
 		"count":   len(wanConfig),
-		"site_id": resolvedSiteID,
+		"site_id": site.ExternalID,
 	}
 	return mcp.NewToolResultJSON(result)
 }
 
 func (s *Server) getRADIUSProfiles(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_radius_profiles")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
 	siteID := request.GetString("site_id", "")
-	resolvedSiteID, err := s.resolveSiteID(ctx, siteID)
+	site, err := s.resolveSite(ctx, siteID)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to resolve site ID", err), nil
+		return toolResultError("Failed to resolve site ID", err), nil
 	}
-	profiles, err := s.networkClient.GetRADIUSProfiles(ctx, resolvedSiteID)
+	profiles, err := s.client(ctx).GetRADIUSProfiles(ctx, site.Name)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get RADIUS profiles", err), nil
+		return toolResultError("Failed to get RADIUS profiles", err), nil
 	}
 	result := map[string]interface{}{
 		"profiles": profiles,
 		"count":    len(profiles),
-		"site_id":  resolvedSiteID,
+		"site_id":  site.ExternalID,
 	}
 	return mcp.NewToolResultJSON(result)
 }
 
 func (s *Server) getDPIApplications(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.logger.Debug("Tool called: get_dpi_applications")
-	if err := s.networkClient.Authenticate(ctx); err != nil {
-		return mcp.NewToolResultErrorFromErr("Authentication failed", err), nil
+	if err := s.client(ctx).Authenticate(ctx); err != nil {
+		return toolResultError("Authentication failed", err), nil
 	}
-	applications, err := s.networkClient.GetDPIApplications(ctx)
+	applications, err := s.client(ctx).GetDPIApplications(ctx)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get DPI applications", err), nil
+		return toolResultError("Failed to get DPI applications", err), nil
 	}
 	result := map[string]interface{}{
 		"applications": applications,
@@ -1316,52 +1982,6 @@ func (s *Server) getDPIApplications(ctx context.Context, request mcp.CallToolReq
 
 // ServeStdio starts the MCP server with stdio transport
 func (s *Server) ServeStdio(ctx context.Context) error {
-	s.logger.Info("Starting UniFi Network MCP Server")
+	s.logger.Info("Starting UniFi Network MCP Server on stdio transport")
 	return server.ServeStdio(s.server)
 }
-
-// ServeHTTP starts the MCP server with HTTP transport
-func (s *Server) ServeHTTP(addr string, ctx context.Context) error {
-	s.logger.Infof("Starting UniFi Network MCP Server on HTTP at %s", addr)
-
-	http.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		// Read the request body
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Failed to read request body", http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
-
-		// Parse the MCP request
-		var requestData map[string]interface{}
-		if err := json.Unmarshal(body, &requestData); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
-			return
-		}
-
-		// Log the request
-		s.logger.Debugf("HTTP MCP request received: %v", requestData)
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		response := map[string]string{
-			"status": "MCP HTTP transport is available",
-			"info":   "This is an HTTP endpoint. Use stdio transport for full MCP protocol support.",
-		}
-		json.NewEncoder(w).Encode(response)
-	})
-
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
-	})
-
-	return http.ListenAndServe(addr, nil)
-}
@@ -0,0 +1,297 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+func newTestServer(opts ...ServerOption) *Server {
+	return NewServer(unifi.NewNetworkClient("https://localhost:8443", "test-api-key", false), opts...)
+}
+
+func TestReadOnlyHidesWriteTools(t *testing.T) {
+	s := newTestServer(WithReadOnly(true))
+	tools := s.server.ListTools()
+
+	if _, ok := tools["get_network_devices"]; !ok {
+		t.Error("expected read tools to remain in read-only mode")
+	}
+	for name, tool := range tools {
+		if isWriteTool(tool.Tool) {
+			t.Errorf("write tool %s exposed in read-only mode", name)
+		}
+	}
+}
+
+func TestSessionOnlyHidesIntegrationTools(t *testing.T) {
+	all := newTestServer().server.ListTools()
+	for name := range integrationTools {
+		if _, ok := all[name]; !ok {
+			t.Errorf("integration tool %s is not registered", name)
+		}
+	}
+
+	session := unifi.NewNetworkClient("https://localhost:8443", "", false, unifi.WithCredentials("admin", "secret"))
+	tools := NewServer(session).server.ListTools()
+	for name := range integrationTools {
+		if _, ok := tools[name]; ok {
+			t.Errorf("integration tool %s exposed without an API key", name)
+		}
+	}
+	if _, ok := tools["get_client_stats"]; !ok {
+		t.Error("expected legacy tools to remain without an API key")
+	}
+
+	// One controller with an API key is enough to expose the tools
+	mixed := NewServer(session, WithControllers([]Controller{
+		{Name: "legacy", Client: session},
+		{Name: "modern", Client: unifi.NewNetworkClient("https://localhost:8443", "test-api-key", false)},
+	}, "legacy")).server.ListTools()
+	if _, ok := mixed["get_network_devices"]; !ok {
+		t.Error("expected integration tools when any controller has an API key")
+	}
+}
+
+func TestToolPatterns(t *testing.T) {
+	s := newTestServer(WithToolPatterns([]string{"get_*"}, []string{"get_dpi_*"}))
+	tools := s.server.ListTools()
+
+	if _, ok := tools["get_wifi_networks"]; !ok {
+		t.Error("expected get_wifi_networks to be allowed")
+	}
+	if _, ok := tools["get_dpi_categories"]; ok {
+		t.Error("expected get_dpi_categories to be denied")
+	}
+	if _, ok := tools["patch_acl_rule"]; ok {
+		t.Error("expected patch_acl_rule to be outside the allow list")
+	}
+}
+
+func TestDeleteRequiresConfirm(t *testing.T) {
+	var deleted []string
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			json.NewEncoder(w).Encode(map[string]interface{}{"meta": map[string]interface{}{"rc": "ok"}, "data": []interface{}{}})
+		case strings.HasSuffix(r.URL.Path, "/integration/v1/info"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"applicationVersion": "9.0.114"})
+		case strings.HasSuffix(r.URL.Path, "/api/self/sites"):
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"_id": "s1", "name": "default", "external_id": "site-uuid"}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer controller.Close()
+
+	s := NewServer(unifi.NewNetworkClient(controller.URL, "test-api-key", false))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "delete_acl_rule"
+	request.Params.Arguments = map[string]interface{}{"rule_id": "r1"}
+
+	result, err := s.deleteACLRule(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Fatalf("expected delete without confirm to fail, got %v %v", result, err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("delete sent without confirm: %v", deleted)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"rule_id": "r1", "confirm": true}
+	result, err = s.deleteACLRule(context.Background(), request)
+	if err != nil || result.IsError {
+		t.Fatalf("expected confirmed delete to succeed, got %v %v", result, err)
+	}
+	if len(deleted) != 1 || !strings.HasSuffix(deleted[0], "/api/s/default/rest/rule/r1") {
+		t.Errorf("unexpected DELETE requests: %v", deleted)
+	}
+}
+
+// TestLegacyPathsUseSiteName checks that tools address the legacy /api/s/{site}
+// API by the site's name: the controller rejects its UUID there, as it does the
+// name on integration API paths
+func TestLegacyPathsUseSiteName(t *testing.T) {
+	ok := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"meta": map[string]interface{}{"rc": "ok"}, "data": data})
+	}
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/integration/v1/info"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"applicationVersion": "9.0.114"})
+		case strings.HasSuffix(r.URL.Path, "/api/self/sites"):
+			ok(w, []map[string]interface{}{{"_id": "s1", "name": "default", "external_id": "site-uuid"}})
+		case strings.Contains(r.URL.Path, "/integration/v1/sites/site-uuid/devices/"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": "dev-1", "macAddress": "aa:bb:cc:00:00:01"})
+		case strings.Contains(r.URL.Path, "/integration/v1/sites/site-uuid/"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{}})
+		case strings.Contains(r.URL.Path, "/api/s/default/"):
+			ok(w, []map[string]interface{}{{"_id": "obj-1", "mac": "aa:bb:cc:00:00:01", "name": "Lobby", "upgradable": true,
+				"port_table": []map[string]interface{}{{"port_idx": 1, "poe_enable": true, "poe_caps": 7}}}})
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer controller.Close()
+
+	s := NewServer(unifi.NewNetworkClient(controller.URL, "test-api-key", false))
+	tools := s.server.ListTools()
+	calls := []struct {
+		tool string
+		args map[string]interface{}
+	}{
+		{"get_device_detailed", map[string]interface{}{"device_id": "dev-1"}},
+		{"get_client_stats", map[string]interface{}{}},
+		{"get_device_tags", map[string]interface{}{}},
+		{"get_traffic_rules", map[string]interface{}{}},
+		{"get_acl_rule_detailed", map[string]interface{}{"acl_rule_id": "obj-1"}},
+		{"patch_acl_rule", map[string]interface{}{"rule_id": "obj-1", "settings": map[string]interface{}{"enabled": false}, "dry_run": true}},
+		{"patch_acl_rule", map[string]interface{}{"rule_id": "obj-1", "settings": map[string]interface{}{"enabled": false}}},
+		{"delete_acl_rule", map[string]interface{}{"rule_id": "obj-1", "confirm": true}},
+		{"restart_device", map[string]interface{}{"device_id": "dev-1"}},
+		{"adopt_device", map[string]interface{}{"mac": "aa:bb:cc:00:00:02"}},
+		{"forget_device", map[string]interface{}{"device_id": "dev-1", "confirm": true}},
+		{"get_available_firmware", map[string]interface{}{}},
+		{"get_outdated_devices", map[string]interface{}{}},
+		{"upgrade_device_firmware", map[string]interface{}{"device_id": "obj-1"}},
+		{"block_client", map[string]interface{}{"mac": "AA-BB-CC-00-00-03"}},
+		{"authorize_guest", map[string]interface{}{"mac": "aa:bb:cc:00:00:03", "minutes": float64(60)}},
+		{"forget_client", map[string]interface{}{"mac": "aa:bb:cc:00:00:03", "confirm": true}},
+		{"get_port_profiles", map[string]interface{}{}},
+		{"get_switch_ports", map[string]interface{}{"device_id": "dev-1"}},
+		{"power_cycle_port", map[string]interface{}{"device_id": "dev-1", "port_idx": float64(1), "dry_run": true}},
+		{"power_cycle_port", map[string]interface{}{"device_id": "dev-1", "port_idx": float64(1)}},
+		{"configure_switch_port", map[string]interface{}{"device_id": "dev-1", "port_idx": float64(1), "enabled": false}},
+	}
+	for _, call := range calls {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = call.tool
+		request.Params.Arguments = call.args
+		result, err := tools[call.tool].Handler(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Errorf("%s failed: %v %v", call.tool, result, err)
+		}
+	}
+}
+
+func TestOutputSchemasMatchReadTools(t *testing.T) {
+	tools := newTestServer().server.ListTools()
+	for name := range outputSchemas() {
+		tool, ok := tools[name]
+		if !ok {
+			t.Errorf("output schema declared for unknown tool %s", name)
+			continue
+		}
+		if isWriteTool(tool.Tool) {
+			t.Errorf("output schema declared for write tool %s", name)
+		}
+		if tool.Tool.OutputSchema.Type != "object" || len(tool.Tool.OutputSchema.Properties) == 0 {
+			t.Errorf("tool %s has an empty output schema", name)
+		}
+	}
+}
+
+func TestPayloadValidation(t *testing.T) {
+	err := wifiNetworkPayload.validate("settings", map[string]interface{}{
+		"vlan":      float64(5000),
+		"wlan_band": "7g",
+		"color":     "blue",
+	}, false)
+	if err == nil {
+		t.Fatal("expected invalid settings to be rejected")
+	}
+	for _, want := range []string{"settings.vlan must be at most 4094", "settings.wlan_band must be one of", "settings.color is not a known field"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+
+	err = wifiNetworkPayload.validate("config", map[string]interface{}{"name": "iot", "security": "wpapsk"}, true)
+	if err == nil || !strings.Contains(err.Error(), "config.x_passphrase is required") {
+		t.Errorf("expected missing passphrase to be rejected, got %v", err)
+	}
+
+	err = aclRulePayload.validate("config", map[string]interface{}{
+		"name": "block cameras", "action": "drop", "ruleset": "LAN_IN", "dst_port": "80,443", "rule_index": float64(2001),
+	}, true)
+	if err != nil {
+		t.Errorf("expected valid ACL rule, got %v", err)
+	}
+}
+
+func TestPatchValidatesBeforeRequest(t *testing.T) {
+	var requests int
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer controller.Close()
+
+	s := NewServer(unifi.NewNetworkClient(controller.URL, "test-api-key", false))
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "patch_wifi_network"
+	request.Params.Arguments = map[string]interface{}{
+		"network_id": "n1",
+		"settings":   map[string]interface{}{"vlan": float64(0)},
+	}
+
+	result, err := s.patchWiFiNetwork(context.Background(), request)
+	if err != nil || !result.IsError {
+		t.Fatalf("expected invalid settings to fail, got %v %v", result, err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no controller requests, got %d", requests)
+	}
+}
+
+func TestToolPayloadsCoverWriteTools(t *testing.T) {
+	tools := newTestServer().server.ListTools()
+	for name, tool := range tools {
+		if !strings.HasPrefix(name, "patch_") && !strings.HasPrefix(name, "create_") {
+			continue
+		}
+		if _, ok := toolPayloads[name]; !ok {
+			t.Errorf("tool %s has no payload schema", name)
+		}
+		if !isWriteTool(tool.Tool) {
+			t.Errorf("tool %s is not a write tool", name)
+		}
+	}
+}
+
+func TestToolResultErrorClassifiesAPIErrors(t *testing.T) {
+	err := fmt.Errorf("failed to get devices: %w", &unifi.APIError{StatusCode: 401, Method: "GET", Path: "/proxy/network/api/self/sites"})
+	result := toolResultError("Failed to get devices", err)
+	if !result.IsError {
+		t.Fatal("expected an error result")
+	}
+	if result.StructuredContent != nil {
+		t.Errorf("expected no structured content, which must match the success output schema, got %v", result.StructuredContent)
+	}
+	details := errorDetails(result)
+	if details["kind"] != ErrorKindUnauthorized || details["status_code"] != 401 {
+		t.Errorf("unexpected error details: %v", details)
+	}
+	if len(result.Content) != 2 {
+		t.Errorf("expected the error text and a hint, got %d content items", len(result.Content))
+	}
+
+	plain := toolResultError("Failed to parse", errors.New("boom"))
+	if plain.StructuredContent != nil || errorDetails(plain) != nil {
+		t.Errorf("expected no error details for other errors, got %v %v", plain.StructuredContent, plain.Meta)
+	}
+}
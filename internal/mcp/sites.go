@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// DefaultSiteCacheTTL is how long a controller's site list is reused before it is fetched again
+const DefaultSiteCacheTTL = 5 * time.Minute
+
+// WithSiteCacheTTL sets how long site lists are cached for resolving site_id
+// arguments; 0 fetches the list on every call
+func WithSiteCacheTTL(ttl time.Duration) ServerOption {
+	return func(s *Server) {
+		s.siteTTL = ttl
+	}
+}
+
+// siteDirectory caches the site list of one controller
+type siteDirectory struct {
+	mu        sync.Mutex
+	sites     []unifi.NetworkSite
+	fetchedAt time.Time
+}
+
+// list returns the cached sites, fetching them when the cache is older than
+// ttl or refresh is set. fetched reports whether the list was just fetched.
+func (d *siteDirectory) list(ctx context.Context, client unifi.NetworkAPI, ttl time.Duration, refresh bool) (sites []unifi.NetworkSite, fetched bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !refresh && !d.fetchedAt.IsZero() && time.Since(d.fetchedAt) < ttl {
+		return d.sites, false, nil
+	}
+
+	sites, err = client.GetSites(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	d.sites = sites
+	d.fetchedAt = time.Now()
+	return sites, true, nil
+}
+
+// invalidate drops the cached site list, so the next call fetches it again
+func (d *siteDirectory) invalidate() {
+	d.mu.Lock()
+	d.sites = nil
+	d.fetchedAt = time.Time{}
+	d.mu.Unlock()
+}
+
+// siteDirectory returns the site cache of the controller selected for the call in ctx
+func (s *Server) siteDirectory(ctx context.Context) *siteDirectory {
+	client := s.client(ctx)
+
+	s.sitesMu.Lock()
+	defer s.sitesMu.Unlock()
+	if s.sites == nil {
+		s.sites = make(map[unifi.NetworkAPI]*siteDirectory)
+	}
+	d, ok := s.sites[client]
+	if !ok {
+		d = &siteDirectory{}
+		s.sites[client] = d
+	}
+	return d
+}
+
+// findSite resolves a site identifier to a site the caller may use. The cached
+// list is fetched again once before a name is reported as unknown, so sites
+// created since the last fetch are found.
+func (s *Server) findSite(ctx context.Context, siteID string) (unifi.NetworkSite, error) {
+	directory := s.siteDirectory(ctx)
+	sites, fetched, err := directory.list(ctx, s.client(ctx), s.siteTTL, false)
+	if err != nil {
+		return unifi.NetworkSite{}, err
+	}
+	matches := matchSites(sites, siteID)
+	if len(matches) == 0 && !fetched {
+		if sites, _, err = directory.list(ctx, s.client(ctx), s.siteTTL, true); err != nil {
+			return unifi.NetworkSite{}, err
+		}
+		matches = matchSites(sites, siteID)
+	}
+
+	switch {
+	case len(sites) == 0:
+		return unifi.NetworkSite{}, fmt.Errorf("no sites available")
+	case len(matches) == 0:
+		return unifi.NetworkSite{}, fmt.Errorf("unknown site %q (known sites: %s)", siteID, s.siteLabels(ctx, sites))
+	case len(matches) > 1:
+		return unifi.NetworkSite{}, fmt.Errorf("site %q is ambiguous (matches: %s); use the site name or ID", siteID, s.siteLabels(ctx, matches))
+	}
+
+	if err := s.authorizeSite(ctx, matches[0]); err != nil {
+		return unifi.NetworkSite{}, err
+	}
+	return matches[0], nil
+}
+
+// matchSites returns the sites a site identifier refers to: the first site for
+// an empty identifier or "default", otherwise the site with that ID, else the
+// sites whose name, else whose description, equals it ignoring case
+func matchSites(sites []unifi.NetworkSite, siteID string) []unifi.NetworkSite {
+	if len(sites) == 0 {
+		return nil
+	}
+	if siteID == "" || siteID == "default" {
+		return sites[:1]
+	}
+
+	for _, site := range sites {
+		if site.ID == siteID || site.ExternalID == siteID {
+			return []unifi.NetworkSite{site}
+		}
+	}
+
+	for _, field := range []func(unifi.NetworkSite) string{
+		func(site unifi.NetworkSite) string { return site.Name },
+		func(site unifi.NetworkSite) string { return site.Desc },
+	} {
+		var matches []unifi.NetworkSite
+		for _, site := range sites {
+			if strings.EqualFold(field(site), siteID) {
+				matches = append(matches, site)
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
+
+// siteLabels describes the sites the caller may use for error messages
+func (s *Server) siteLabels(ctx context.Context, sites []unifi.NetworkSite) string {
+	labels := []string{}
+	for _, site := range sites {
+		if !s.canUseSite(ctx, site) {
+			continue
+		}
+		label := site.Name
+		if site.Desc != "" && site.Desc != site.Name {
+			label = fmt.Sprintf("%s (%s)", site.Desc, site.Name)
+		}
+		labels = append(labels, label)
+	}
+	if len(labels) == 0 {
+		return "none"
+	}
+	return strings.Join(labels, ", ")
+}
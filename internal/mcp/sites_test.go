@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+func TestResolveSiteID(t *testing.T) {
+	fetches := 0
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/integration/v1/info"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"applicationVersion": "9.0.114"})
+		case strings.HasSuffix(r.URL.Path, "/api/self/sites"):
+			fetches++
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"_id": "s1", "name": "default", "desc": "Headquarters", "external_id": "uuid-1"},
+					{"_id": "s2", "name": "x7k2m3", "desc": "Warehouse", "external_id": "uuid-2"},
+					{"_id": "s3", "name": "b1", "desc": "Branch", "external_id": "uuid-3"},
+					{"_id": "s4", "name": "b2", "desc": "branch", "external_id": "uuid-4"},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer controller.Close()
+
+	s := NewServer(unifi.NewNetworkClient(controller.URL, "key", false))
+	ctx := context.Background()
+
+	for siteID, want := range map[string]string{
+		"":             "uuid-1",
+		"default":      "uuid-1",
+		"headquarters": "uuid-1",
+		"X7K2M3":       "uuid-2",
+		"warehouse":    "uuid-2",
+		"s3":           "uuid-3",
+		"uuid-4":       "uuid-4",
+		"B2":           "uuid-4",
+	} {
+		got, err := s.resolveSiteID(ctx, siteID)
+		if err != nil || got != want {
+			t.Errorf("resolveSiteID(%q) = %q, %v; want %q", siteID, got, err, want)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected the site list to be fetched once, got %d fetches", fetches)
+	}
+
+	if _, err := s.resolveSiteID(ctx, "branch"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected an ambiguous site error, got %v", err)
+	}
+	if _, err := s.resolveSiteID(ctx, "nowhere"); err == nil || !strings.Contains(err.Error(), "unknown site") ||
+		!strings.Contains(err.Error(), "Warehouse (x7k2m3)") {
+		t.Errorf("expected an unknown site error listing the sites, got %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("expected an unknown site to fetch the list again, got %d fetches", fetches)
+	}
+
+	// Listing sites refreshes the cache
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_network_sites"
+	if result, err := s.getNetworkSites(ctx, request); err != nil || result.IsError {
+		t.Fatalf("get_network_sites failed: %v %v", result, err)
+	}
+	if fetches != 3 {
+		t.Errorf("expected get_network_sites to fetch the list, got %d fetches", fetches)
+	}
+	s.resolveSiteID(ctx, "warehouse")
+	if fetches != 3 {
+		t.Errorf("expected the refreshed list to be reused, got %d fetches", fetches)
+	}
+}
+
+func TestSiteNotFoundRefreshesSiteCache(t *testing.T) {
+	fetches := 0
+	deleted := false
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/integration/v1/info"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"applicationVersion": "9.0.114"})
+		case strings.HasSuffix(r.URL.Path, "/api/self/sites"):
+			fetches++
+			sites := []map[string]interface{}{{"_id": "s1", "name": "default", "desc": "Headquarters", "external_id": "uuid-1"}}
+			if !deleted {
+				sites = append(sites, map[string]interface{}{"_id": "s2", "name": "x7k2m3", "desc": "Warehouse", "external_id": "uuid-2"})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": sites})
+		case strings.HasSuffix(r.URL.Path, "/api/s/x7k2m3/rest/tag"):
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"meta": {"rc": "error", "msg": "api.err.NoSiteContext"}, "data": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer controller.Close()
+
+	s := NewServer(unifi.NewNetworkClient(controller.URL, "key", false))
+	ctx := context.Background()
+	if _, err := s.resolveSiteID(ctx, "warehouse"); err != nil || fetches != 1 {
+		t.Fatalf("expected the warehouse to resolve from one fetch, got %v after %d fetches", err, fetches)
+	}
+
+	// The site is deleted while the list is cached; the controller's answer drops the cache
+	deleted = true
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_device_tags"
+	request.Params.Arguments = map[string]interface{}{"site_id": "warehouse"}
+	result, err := s.logToolCall(s.getDeviceTags)(ctx, request)
+	if err != nil || !result.IsError || errorDetails(result)["kind"] != ErrorKindSiteNotFound {
+		t.Fatalf("expected a site_not_found error, got %v %v", result, err)
+	}
+
+	if _, err := s.resolveSiteID(ctx, "warehouse"); err == nil || !strings.Contains(err.Error(), "unknown site") || fetches != 2 {
+		t.Errorf("expected the stale site to be refetched and reported unknown, got %v after %d fetches", err, fetches)
+	}
+}
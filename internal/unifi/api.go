@@ -0,0 +1,102 @@
+package unifi
+
+import "context"
+
+// NetworkAPI is the Network application API as used by the MCP server.
+// NetworkClient implements it against a controller; unifitest.FakeNetwork
+// implements it in memory for tests.
+type NetworkAPI interface {
+	// Connection
+	BaseURL() string
+	ControllerType() ControllerType
+	ReadOnly() bool
+	IntegrationAPI() bool
+	Authenticate(ctx context.Context) error
+	CheckEndpointHealth(ctx context.Context) (map[string]interface{}, error)
+	GetInfo(ctx context.Context) (*NetworkApplicationInfo, error)
+
+	// Sites, devices and clients
+	GetSites(ctx context.Context) ([]NetworkSite, error)
+	GetHealth(ctx context.Context, siteID string) (*NetworkHealthSubsystem, error)
+	GetDevices(ctx context.Context, siteID string) ([]NetworkDevice, error)
+	GetDeviceDetailed(ctx context.Context, site NetworkSite, deviceID string) (*NetworkDeviceDetail, error)
+	GetDeviceStats(ctx context.Context, siteID, deviceID string) (*NetworkDevice, error)
+	GetPendingDevices(ctx context.Context) ([]NetworkPendingDevice, error)
+	GetDeviceTags(ctx context.Context, siteID string) ([]NetworkDeviceTag, error)
+	GetClients(ctx context.Context, siteID string, limit, offset int) ([]NetworkConnectedClient, error)
+	GetClientDetailed(ctx context.Context, siteID, clientMAC string) (*NetworkConnectedClient, error)
+	GetClientStats(ctx context.Context, siteID string) ([]NetworkClientDevice, error)
+
+	// Configuration reads
+	GetWiFiNetworks(ctx context.Context, siteID string) ([]NetworkWiFiNetwork, error)
+	GetWiFiNetworkDetailed(ctx context.Context, siteID, networkID string) (*NetworkConfig, error)
+	GetWiFiBroadcasts(ctx context.Context, siteID string) ([]NetworkWiFiBroadcast, error)
+	GetFirewallZones(ctx context.Context, siteID string) ([]NetworkFirewallZone, error)
+	GetFirewallZoneDetailed(ctx context.Context, siteID, zoneID string) (*NetworkFirewallZone, error)
+	GetACLRules(ctx context.Context, siteID string) ([]NetworkACLRule, error)
+	GetACLRuleDetailed(ctx context.Context, siteID, ruleID string) (*NetworkACLRule, error)
+	GetHotspotVouchers(ctx context.Context, siteID string) ([]NetworkHotspotVoucher, error)
+	GetHotspotVoucherDetailed(ctx context.Context, siteID, voucherID string) (*NetworkHotspotVoucher, error)
+	GetTrafficRules(ctx context.Context, siteID string) ([]NetworkTrafficRule, error)
+	GetTrafficRuleDetailed(ctx context.Context, siteID, ruleID string) (*NetworkTrafficRule, error)
+	GetVPNServers(ctx context.Context, siteID string) ([]NetworkVPNServer, error)
+	GetVPNTunnels(ctx context.Context, siteID string) ([]NetworkVPNTunnel, error)
+	GetVPNTunnelDetailed(ctx context.Context, siteID, tunnelID string) (*NetworkVPNTunnel, error)
+	GetWANConfig(ctx context.Context, siteID string) ([]NetworkWANConfig, error)
+	GetRADIUSProfiles(ctx context.Context, siteID string) ([]NetworkRADIUSProfile, error)
+	GetDPICategories(ctx context.Context) ([]NetworkDPICategory, error)
+	GetDPIApplications(ctx context.Context) ([]NetworkDPIApplication, error)
+	GetAvailableFirmware(ctx context.Context, siteID string) ([]NetworkFirmware, error)
+	GetDeviceFirmware(ctx context.Context, siteID string) ([]NetworkDeviceFirmware, error)
+	GetPortProfiles(ctx context.Context, siteID string) ([]NetworkPortProfile, error)
+	GetPortProfileDetailed(ctx context.Context, siteID, profileID string) (*NetworkPortProfile, error)
+	GetObject(ctx context.Context, siteID string, resource Resource, id string) (map[string]interface{}, error)
+
+	// Writes
+	PatchWiFiNetwork(ctx context.Context, siteID, networkID string, settings map[string]interface{}) (map[string]interface{}, error)
+	PatchFirewallZone(ctx context.Context, siteID, zoneID string, settings map[string]interface{}) (map[string]interface{}, error)
+	PatchACLRule(ctx context.Context, siteID, ruleID string, settings map[string]interface{}) (map[string]interface{}, error)
+	PatchHotspotVoucher(ctx context.Context, siteID, voucherID string, settings map[string]interface{}) (map[string]interface{}, error)
+	PatchTrafficRule(ctx context.Context, siteID, ruleID string, settings map[string]interface{}) (map[string]interface{}, error)
+	CreateWiFiNetwork(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error)
+	CreateFirewallZone(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error)
+	CreateACLRule(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error)
+	CreateHotspotVoucher(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error)
+	CreateTrafficRule(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error)
+	CreateVPNTunnel(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error)
+	PatchPortProfile(ctx context.Context, siteID, profileID string, settings map[string]interface{}) (map[string]interface{}, error)
+	CreatePortProfile(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error)
+	DeletePortProfile(ctx context.Context, siteID, profileID string) error
+	PatchDevice(ctx context.Context, siteID, deviceID string, settings map[string]interface{}) (map[string]interface{}, error)
+	DeleteWiFiNetwork(ctx context.Context, siteID, networkID string) error
+	DeleteFirewallZone(ctx context.Context, siteID, zoneID string) error
+	DeleteACLRule(ctx context.Context, siteID, ruleID string) error
+	DeleteHotspotVoucher(ctx context.Context, siteID, voucherID string) error
+	DeleteTrafficRule(ctx context.Context, siteID, ruleID string) error
+	DeleteVPNTunnel(ctx context.Context, siteID, tunnelID string) error
+
+	// Device commands
+	RestartDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	LocateDevice(ctx context.Context, siteID, mac string, enabled bool) (map[string]interface{}, error)
+	ForceProvisionDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	AdoptDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	ForgetDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	UpgradeDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	PowerCyclePort(ctx context.Context, siteID, mac string, portIdx int) (map[string]interface{}, error)
+
+	// Client commands
+	BlockClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	UnblockClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	KickClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	ForgetClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+	AuthorizeGuest(ctx context.Context, siteID, mac string, auth GuestAuthorization) (map[string]interface{}, error)
+	UnauthorizeGuest(ctx context.Context, siteID, mac string) (map[string]interface{}, error)
+
+	// Dry runs
+	PlanPatch(siteID string, resource Resource, id string, settings map[string]interface{}) RequestPlan
+	PlanCreate(siteID string, resource Resource, config map[string]interface{}) RequestPlan
+	PlanDelete(siteID string, resource Resource, id string) RequestPlan
+	PlanPowerCyclePort(siteID, mac string, portIdx int) RequestPlan
+	PlanRestartDevice(siteID, mac string) RequestPlan
+	PlanForgetDevice(siteID, mac string) RequestPlan
+}
@@ -0,0 +1,134 @@
+package unifi
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// WriteRecord describes a single write request sent, or refused, by the client
+type WriteRecord struct {
+	Method     string
+	URL        string
+	SiteID     string
+	Resource   string
+	ObjectID   string
+	Request    map[string]interface{}
+	Before     map[string]interface{}
+	After      map[string]interface{}
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+}
+
+// AuditRecorder receives a record of every write request the client attempts.
+// The context is the one passed to the client method, so recorders can read
+// caller details attached by higher layers.
+type AuditRecorder interface {
+	RecordWrite(ctx context.Context, record WriteRecord)
+}
+
+func newWriteRecord(method, url string, payload map[string]interface{}) WriteRecord {
+	site, resource, id := parseRESTURL(url)
+	if id == "" && strings.HasPrefix(resource, "cmd/") {
+		// Commands name the device or client they act on in the body
+		if mac, ok := payload["mac"].(string); ok {
+			id = mac
+		} else if macs, ok := payload["macs"].([]string); ok && len(macs) == 1 {
+			id = macs[0]
+		}
+	}
+	return WriteRecord{
+		Method:   method,
+		URL:      url,
+		SiteID:   site,
+		Resource: resource,
+		ObjectID: id,
+		Request:  payload,
+	}
+}
+
+func (nc *NetworkClient) recordWrite(ctx context.Context, record WriteRecord) {
+	if nc.auditor != nil {
+		record.Request = redactSecrets(record.Request)
+		record.Before = redactSecrets(record.Before)
+		record.After = redactSecrets(record.After)
+		nc.auditor.RecordWrite(ctx, record)
+	}
+}
+
+// redactedValue replaces the values of sensitive keys in audit records
+const redactedValue = "[redacted]"
+
+// redactSecrets returns a copy of object with the values of sensitive keys replaced,
+// so WiFi passphrases, RADIUS secrets and the like never reach an audit log. Nested
+// objects are redacted too; object itself is left untouched as it is still in use.
+func redactSecrets(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		if isSensitiveKey(key) {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = redactValue(value)
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactSecrets(v)
+	case []map[string]interface{}:
+		items := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactSecrets(item)
+		}
+		return items
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = redactValue(item)
+		}
+		return items
+	}
+	return value
+}
+
+// isSensitiveKey reports whether a field holds a secret. The controller prefixes the
+// secrets of its objects with x_, as in x_passphrase; the rest are matched by name.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "x_") {
+		return true
+	}
+	for _, word := range []string{"passphrase", "secret", "psk", "password"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRESTURL extracts the site, resource and object ID from a .../api/s/{site}/rest/{resource}[/{id}] URL.
+// Other URLs yield the site (when present) and the remaining path as the resource.
+func parseRESTURL(url string) (site, resource, id string) {
+	_, path, ok := strings.Cut(url, "/api/s/")
+	if !ok {
+		return "", "", ""
+	}
+	path, _, _ = strings.Cut(path, "?")
+	parts := strings.Split(path, "/")
+	site = parts[0]
+	if len(parts) >= 3 && parts[1] == "rest" {
+		resource = parts[2]
+		if len(parts) >= 4 {
+			id = parts[3]
+		}
+		return site, resource, id
+	}
+	return site, strings.Join(parts[1:], "/"), ""
+}
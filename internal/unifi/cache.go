@@ -0,0 +1,279 @@
+package unifi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache stores successful GET responses for the client's response cache.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the unexpired response stored under key
+	Get(key string) (*CachedResponse, bool)
+	// Set stores resp under key for ttl
+	Set(key string, resp *CachedResponse, ttl time.Duration)
+	// Invalidate drops the responses of resource, or every response when resource is empty
+	Invalidate(resource string)
+}
+
+// CachedResponse is a response body kept by a ResponseCache
+type CachedResponse struct {
+	Resource   string // cache resource the response belongs to, see DefaultCacheTTLs
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// DefaultCacheTTLs returns how long responses of each cache resource are kept.
+// Resources missing from the map, or with a TTL of 0, are not cached.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		"devices":  30 * time.Second,
+		"clients":  15 * time.Second,
+		"networks": time.Minute,
+		"firewall": time.Minute,
+		"acl":      time.Minute,
+		"hotspot":  time.Minute,
+		"traffic":  time.Minute,
+		"vpn":      time.Minute,
+		"settings": time.Minute,
+		"dpi":      time.Hour,
+	}
+}
+
+// cacheRules map request paths to cache resources. The integration API and the
+// legacy API paths of a resource share one resource, so a write through either
+// invalidates reads through both.
+var cacheRules = []struct {
+	resource string
+	pattern  *regexp.Regexp
+}{
+	{"devices", regexp.MustCompile(`/sites/[^/]+/devices(/|$)`)},
+	{"devices", regexp.MustCompile(`/api/s/[^/]+/(stat/device|rest/device|cmd/devmgr)(/|$)`)},
+	{"clients", regexp.MustCompile(`/sites/[^/]+/clients(/|$)`)},
+	{"clients", regexp.MustCompile(`/api/s/[^/]+/(stat/sta|rest/user|cmd/stamgr)(/|$)`)},
+	{"networks", regexp.MustCompile(`/sites/[^/]+/(networks|wifi/broadcasts)(/|$)`)},
+	{"networks", regexp.MustCompile(`/api/s/[^/]+/rest/(networkconf|wlanconf)(/|$)`)},
+	{"firewall", regexp.MustCompile(`/sites/[^/]+/firewall/zones(/|$)`)},
+	{"firewall", regexp.MustCompile(`/api/s/[^/]+/rest/firewallzone(/|$)`)},
+	{"acl", regexp.MustCompile(`/sites/[^/]+/acl-rules(/|$)`)},
+	{"acl", regexp.MustCompile(`/api/s/[^/]+/rest/rule(/|$)`)},
+	{"hotspot", regexp.MustCompile(`/sites/[^/]+/hotspot/vouchers(/|$)`)},
+	{"hotspot", regexp.MustCompile(`/api/s/[^/]+/rest/hotspotop(/|$)`)},
+	{"traffic", regexp.MustCompile(`/api/s/[^/]+/rest/trafficrule(/|$)`)},
+	{"vpn", regexp.MustCompile(`/sites/[^/]+/vpn/servers(/|$)`)},
+	{"vpn", regexp.MustCompile(`/api/s/[^/]+/rest/vpnserverconfig(/|$)`)},
+	{"settings", regexp.MustCompile(`/api/s/[^/]+/rest/(tag|wanconf|radiusprofile|portconf)(/|$)`)},
+	{"dpi", regexp.MustCompile(`/dpi/(categories|applications)(/|$)`)},
+	{"firmware", regexp.MustCompile(`/api/s/[^/]+/cmd/firmware(/|$)`)}, // listing is a POST, so it must not invalidate everything
+}
+
+// cacheResource returns the cache resource a request path belongs to, or "" for none
+func cacheResource(path string) string {
+	for _, rule := range cacheRules {
+		if rule.pattern.MatchString(path) {
+			return rule.resource
+		}
+	}
+	return ""
+}
+
+// ParseCacheTTLs parses comma-separated resource=duration pairs such as
+// "devices=10s,dpi=0" into overrides of DefaultCacheTTLs
+func ParseCacheTTLs(value string) (map[string]time.Duration, error) {
+	defaults := DefaultCacheTTLs()
+	ttls := make(map[string]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		resource, duration, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid cache TTL %q: want resource=duration", pair)
+		}
+		resource = strings.TrimSpace(resource)
+		if _, known := defaults[resource]; !known {
+			resources := make([]string, 0, len(defaults))
+			for name := range defaults {
+				resources = append(resources, name)
+			}
+			sort.Strings(resources)
+			return nil, fmt.Errorf("unknown cache resource %q (want one of %s)", resource, strings.Join(resources, ", "))
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid cache TTL for %s: %q", resource, duration)
+		}
+		ttls[resource] = ttl
+	}
+	return ttls, nil
+}
+
+// WithResponseCache caches GET responses in cache for the TTL of their resource.
+// Writes invalidate the cached responses of the resource they touch, and writes
+// to paths that belong to no resource invalidate the whole cache.
+func WithResponseCache(cache ResponseCache, ttls map[string]time.Duration) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.cache = cache
+		nc.cacheTTLs = ttls
+	}
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose requests skip cached responses. Fresh
+// responses are still stored, so later calls see them.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(noCacheKey{}).(bool)
+	return bypass
+}
+
+// do sends req through the response cache, if the client has one, and the retry
+// policy. Integration API requests of a client without an API key fail unsent.
+func (nc *NetworkClient) do(req *http.Request) (*http.Response, error) {
+	if err := nc.checkIntegrationAPI(req); err != nil {
+		return nil, err
+	}
+	if nc.cache == nil {
+		return nc.send(req)
+	}
+
+	resource := cacheResource(req.URL.Path)
+	if !isIdempotent(req.Method) {
+		resp, err := nc.send(req)
+		// Invalidate whatever the outcome: a failed write may still have been applied
+		nc.invalidateCache(resource)
+		return resp, err
+	}
+
+	ttl := nc.cacheTTLs[resource]
+	if req.Method != http.MethodGet || resource == "" || ttl <= 0 {
+		return nc.send(req)
+	}
+
+	key := req.Method + " " + req.URL.String()
+	if !cacheBypassed(req.Context()) {
+		if cached, ok := nc.cache.Get(key); ok {
+			nc.logger.WithField("url", req.URL.String()).Debug("Serving cached response")
+			return cached.response(req), nil
+		}
+	}
+
+	gen := nc.cacheGen.Load()
+	resp, err := nc.send(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// A write invalidated the cache while this request was in flight, so the
+	// response may predate it
+	if nc.cacheGen.Load() == gen {
+		nc.cache.Set(key, &CachedResponse{
+			Resource:   resource,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       body,
+		}, ttl)
+	}
+	return resp, nil
+}
+
+// invalidateCache drops the cached responses of resource, or all of them for ""
+func (nc *NetworkClient) invalidateCache(resource string) {
+	nc.cacheGen.Add(1)
+	nc.cache.Invalidate(resource)
+	nc.logger.WithField("resource", resource).Debug("Invalidated cached responses")
+}
+
+// response rebuilds an HTTP response for req from the cached one
+func (c *CachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.StatusCode, http.StatusText(c.StatusCode)),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// MemoryCache is an in-memory ResponseCache
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	resp    *CachedResponse
+	expires time.Time
+}
+
+// NewMemoryCache creates an empty in-memory response cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+// Get returns the unexpired response stored under key
+func (c *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// Set stores resp under key for ttl, dropping expired entries along the way
+func (c *MemoryCache) Set(key string, resp *CachedResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = memoryCacheEntry{resp: resp, expires: now.Add(ttl)}
+}
+
+// Invalidate drops the responses of resource, or every response when resource is empty
+func (c *MemoryCache) Invalidate(resource string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if resource == "" || entry.resp.Resource == resource {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -1,7 +1,22 @@
 package unifi
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNetworkClientCreation(t *testing.T) {
@@ -13,3 +28,618 @@ func TestNetworkClientCreation(t *testing.T) {
 		t.Errorf("Expected baseURL to be set, got %s", client.baseURL)
 	}
 }
+
+func TestReadOnlyClientRefusesWrites(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithReadOnly(true))
+	if !client.ReadOnly() {
+		t.Fatal("expected client to be read-only")
+	}
+
+	if _, err := client.PatchWiFiNetwork(context.Background(), "default", "net1", map[string]interface{}{"enabled": false}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from PATCH, got %v", err)
+	}
+	if _, err := client.CreateACLRule(context.Background(), "default", map[string]interface{}{"name": "x"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("expected ErrReadOnly from POST, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests to reach the controller, got %d", requests)
+	}
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	var gets, patches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			patches++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		gets++
+		if gets < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    10 * time.Millisecond,
+	}))
+
+	ctx, stats := WithRetryStats(context.Background())
+	if _, err := client.GetACLRules(ctx, "default"); err != nil {
+		t.Fatalf("expected GET to succeed after retries, got %v", err)
+	}
+	if gets != 3 || stats.Retries() != 2 {
+		t.Errorf("expected 3 attempts and 2 retries, got %d attempts and %d retries", gets, stats.Retries())
+	}
+
+	if _, err := client.PatchACLRule(context.Background(), "default", "r1", map[string]interface{}{"enabled": true}); err == nil {
+		t.Fatal("expected PATCH to fail")
+	}
+	if patches != 1 {
+		t.Errorf("expected writes not to be retried by default, got %d attempts", patches)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if wait, ok := parseRetryAfter("7"); !ok || wait != 7*time.Second {
+		t.Errorf("expected 7s, got %v %v", wait, ok)
+	}
+	if wait, ok := parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)); !ok || wait != 0 {
+		t.Errorf("expected a past date to mean no wait, got %v %v", wait, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("expected an invalid value to be ignored")
+	}
+}
+
+func TestAPIErrorFromEnvelopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"meta": {"rc": "error", "msg": "api.err.InvalidPayload"}, "data": []}`))
+		case http.MethodPost:
+			// Legacy endpoints can report errors with a 200 status
+			w.Write([]byte(`{"meta": {"rc": "error", "msg": "api.err.NameAlreadyExists"}, "data": []}`))
+		default:
+			w.Header().Set("X-Request-Id", "header-id")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"statusCode": 404, "code": "api.request.not-found", "message": "Site not found", "requestId": "req-42"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+	_, err := client.GetACLRules(context.Background(), "default")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != 404 || apiErr.Method != "GET" || apiErr.Code != "api.request.not-found" ||
+		apiErr.Message != "Site not found" || apiErr.RequestID != "req-42" {
+		t.Errorf("unexpected integration error: %+v", apiErr)
+	}
+	if !IsNotFound(err) || IsValidation(err) {
+		t.Errorf("expected a not-found error, got %v", err)
+	}
+
+	_, err = client.PatchACLRule(context.Background(), "default", "r1", map[string]interface{}{"enabled": true})
+	if !IsValidation(err) || !errors.As(err, &apiErr) || apiErr.Code != "api.err.InvalidPayload" {
+		t.Errorf("expected legacy validation error, got %v", err)
+	}
+
+	_, err = client.CreateACLRule(context.Background(), "default", map[string]interface{}{"name": "x"})
+	if !errors.As(err, &apiErr) || apiErr.Code != "api.err.NameAlreadyExists" || apiErr.StatusCode != 400 {
+		t.Errorf("expected error from 200 legacy envelope, got %v", err)
+	}
+}
+
+func TestAuthenticateDetectsClassicController(t *testing.T) {
+	probes := 0
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/integration/v1/info":
+			probes++
+			w.Write([]byte(`{"applicationVersion": "9.0.114"}`))
+		case "/api/s/default/rest/rule":
+			w.Write([]byte(`{"meta": {"rc": "ok"}, "data": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	for i := 0; i < 2; i++ {
+		if err := client.Authenticate(context.Background()); err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+	}
+	if client.ControllerType() != ControllerClassic {
+		t.Errorf("expected classic controller, got %s", client.ControllerType())
+	}
+	if probes != 1 {
+		t.Errorf("expected the probe result to be cached, got %d probes", probes)
+	}
+
+	if _, err := client.GetTrafficRules(context.Background(), "default"); err == nil {
+		t.Error("expected unknown path to fail")
+	}
+	if last := paths[len(paths)-1]; strings.HasPrefix(last, "/proxy/network") {
+		t.Errorf("expected classic URLs without the UniFi OS prefix, got %s", last)
+	}
+}
+
+func TestAuthenticateRejectsInvalidKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewNetworkClient(server.URL, "wrong-key", false, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	err := client.Authenticate(context.Background())
+	if !IsUnauthorized(err) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestSessionLoginAndRelogin(t *testing.T) {
+	logins := 0
+	expired := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth/login":
+			logins++
+			http.SetCookie(w, &http.Cookie{Name: "TOKEN", Value: fmt.Sprintf("session-%d", logins), Path: "/"})
+			w.Header().Set("X-CSRF-Token", fmt.Sprintf("csrf-%d", logins))
+			w.Write([]byte(`{}`))
+		case "/proxy/network/api/s/default/rest/rule/r1":
+			cookie, err := r.Cookie("TOKEN")
+			if err != nil || r.Header.Get("X-API-KEY") != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !expired && cookie.Value == "session-1" {
+				// The first session expires before the write
+				expired = true
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Header.Get("X-CSRF-Token") != "csrf-2" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"meta": {"rc": "ok"}, "data": [{"_id": "r1", "enabled": true}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewNetworkClient(server.URL, "", false, WithCredentials("admin", "secret"))
+	if err := client.Authenticate(context.Background()); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if client.ControllerType() != ControllerUniFiOS {
+		t.Errorf("expected UniFi OS, got %s", client.ControllerType())
+	}
+
+	if _, err := client.PatchACLRule(context.Background(), "default", "r1", map[string]interface{}{"enabled": true}); err != nil {
+		t.Fatalf("expected PATCH to succeed after logging in again, got %v", err)
+	}
+	if logins != 2 {
+		t.Errorf("expected 2 logins, got %d", logins)
+	}
+
+	// The integration API does not take sessions, so its requests fail before being sent
+	if client.IntegrationAPI() {
+		t.Error("expected a client without an API key to have no integration API")
+	}
+	if _, err := client.GetDevices(context.Background(), "site-uuid"); !errors.Is(err, ErrAPIKeyRequired) {
+		t.Errorf("expected GetDevices to need an API key, got %v", err)
+	}
+	site := NetworkSite{Name: "default", ExternalID: "site-uuid"}
+	if _, err := client.GetDeviceDetailed(context.Background(), site, "dev-1"); !errors.Is(err, ErrAPIKeyRequired) {
+		t.Errorf("expected a device ID lookup to need an API key, got %v", err)
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	gets := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets[r.URL.Path]++
+		}
+		w.Write([]byte(`{"data": [{"_id": "z1"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithResponseCache(NewMemoryCache(), DefaultCacheTTLs()))
+	ctx := context.Background()
+	zones := "/proxy/network/integration/v1/sites/default/firewall/zones"
+	devices := "/proxy/network/integration/v1/sites/default/devices"
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetFirewallZones(ctx, "default"); err != nil {
+			t.Fatalf("GetFirewallZones failed: %v", err)
+		}
+		if _, err := client.GetDevices(ctx, "default"); err != nil {
+			t.Fatalf("GetDevices failed: %v", err)
+		}
+	}
+	if gets[zones] != 1 || gets[devices] != 1 {
+		t.Fatalf("expected repeated reads to be served from the cache, got %v", gets)
+	}
+
+	client.GetFirewallZones(WithoutCache(ctx), "default")
+	if gets[zones] != 2 {
+		t.Errorf("expected WithoutCache to fetch fresh data, got %d fetches", gets[zones])
+	}
+
+	// A write through the legacy API invalidates the integration API reads of the same resource
+	if _, err := client.PatchFirewallZone(ctx, "default", "z1", map[string]interface{}{"name": "LAN"}); err != nil {
+		t.Fatalf("PatchFirewallZone failed: %v", err)
+	}
+	client.GetFirewallZones(ctx, "default")
+	client.GetDevices(ctx, "default")
+	if gets[zones] != 3 || gets[devices] != 1 {
+		t.Errorf("expected the write to invalidate only firewall zones, got %v", gets)
+	}
+
+	if _, err := ParseCacheTTLs("devices=10s, dpi=0"); err != nil {
+		t.Errorf("ParseCacheTTLs failed: %v", err)
+	}
+	if _, err := ParseCacheTTLs("sites=1m"); err == nil {
+		t.Error("expected an unknown cache resource to be rejected")
+	}
+}
+
+// auditRecorderFunc adapts a function to the AuditRecorder interface
+type auditRecorderFunc func(ctx context.Context, record WriteRecord)
+
+func (f auditRecorderFunc) RecordWrite(ctx context.Context, record WriteRecord) { f(ctx, record) }
+
+func TestDeviceCommands(t *testing.T) {
+	var commands []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		target := body["mac"]
+		if macs, ok := body["macs"]; ok {
+			target = macs
+		}
+		commands = append(commands, fmt.Sprintf("%s %s %v %v", r.Method, r.URL.Path, body["cmd"], target))
+		w.Write([]byte(`{"meta": {"rc": "ok"}, "data": []}`))
+	}))
+	defer server.Close()
+
+	var records []WriteRecord
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithAuditRecorder(auditRecorderFunc(func(ctx context.Context, record WriteRecord) {
+		records = append(records, record)
+	})))
+	ctx := context.Background()
+	mac := "AA:BB:CC:00:00:01"
+
+	client.RestartDevice(ctx, "default", mac)
+	client.LocateDevice(ctx, "default", mac, true)
+	client.LocateDevice(ctx, "default", mac, false)
+	client.ForceProvisionDevice(ctx, "default", mac)
+	client.AdoptDevice(ctx, "default", mac)
+	client.PowerCyclePort(ctx, "default", mac, 3)
+	if _, err := client.ForgetDevice(ctx, "default", mac); err != nil {
+		t.Fatalf("ForgetDevice failed: %v", err)
+	}
+
+	devmgr := "POST /proxy/network/api/s/default/cmd/devmgr "
+	want := []string{
+		devmgr + "restart aa:bb:cc:00:00:01",
+		devmgr + "set-locate aa:bb:cc:00:00:01",
+		devmgr + "unset-locate aa:bb:cc:00:00:01",
+		devmgr + "force-provision aa:bb:cc:00:00:01",
+		devmgr + "adopt aa:bb:cc:00:00:01",
+		devmgr + "power-cycle aa:bb:cc:00:00:01",
+		"POST /proxy/network/api/s/default/cmd/sitemgr delete-device [aa:bb:cc:00:00:01]",
+	}
+	if strings.Join(commands, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected commands:\n%s", strings.Join(commands, "\n"))
+	}
+
+	if len(records) != len(want) || records[0].Resource != "cmd/devmgr" || records[0].ObjectID != "aa:bb:cc:00:00:01" ||
+		records[6].ObjectID != "aa:bb:cc:00:00:01" {
+		t.Errorf("expected audit records naming the device, got %+v", records)
+	}
+
+	// A planned power cycle is the request PowerCyclePort sends
+	plan := client.PlanPowerCyclePort("default", mac, 3)
+	if plan.URL != server.URL+"/proxy/network/api/s/default/cmd/devmgr" || plan.Body["port_idx"] != 3 || records[5].Request["port_idx"] != 3 {
+		t.Errorf("unexpected power cycle plan %+v or payload %v", plan, records[5].Request)
+	}
+	if plan := client.PlanRestartDevice("default", mac); plan.URL != server.URL+"/proxy/network/api/s/default/cmd/devmgr" ||
+		!reflect.DeepEqual(plan.Body, records[0].Request) {
+		t.Errorf("unexpected restart plan %+v, sent %v", plan, records[0].Request)
+	}
+	if plan := client.PlanForgetDevice("default", mac); plan.URL != server.URL+"/proxy/network/api/s/default/cmd/sitemgr" ||
+		!reflect.DeepEqual(plan.Body, records[6].Request) {
+		t.Errorf("unexpected forget plan %+v, sent %v", plan, records[6].Request)
+	}
+}
+
+func TestClientCommands(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"meta": {"rc": "ok"}, "data": []}`))
+	}))
+	defer server.Close()
+
+	var records []WriteRecord
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithAuditRecorder(auditRecorderFunc(func(ctx context.Context, record WriteRecord) {
+		records = append(records, record)
+	})))
+	ctx := context.Background()
+
+	mac := "aa:bb:cc:00:00:03"
+
+	client.BlockClient(ctx, "default", mac)
+	client.KickClient(ctx, "default", mac)
+	client.ForgetClient(ctx, "default", mac)
+	client.AuthorizeGuest(ctx, "default", mac, GuestAuthorization{Minutes: 60, DownKbps: 2048, APMAC: "aa:bb:cc:00:00:09"})
+
+	stamgr := "/proxy/network/api/s/default/cmd/stamgr "
+	want := []string{
+		stamgr + `{"cmd":"block-sta","mac":"aa:bb:cc:00:00:03"}`,
+		stamgr + `{"cmd":"kick-sta","mac":"aa:bb:cc:00:00:03"}`,
+		stamgr + `{"cmd":"forget-sta","macs":["aa:bb:cc:00:00:03"]}`,
+		stamgr + `{"ap_mac":"aa:bb:cc:00:00:09","cmd":"authorize-guest","down":2048,"mac":"aa:bb:cc:00:00:03","minutes":60}`,
+	}
+	if strings.Join(bodies, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected commands:\n%s", strings.Join(bodies, "\n"))
+	}
+	if len(records) != len(want) || records[2].Resource != "cmd/stamgr" || records[2].ObjectID != "aa:bb:cc:00:00:03" {
+		t.Errorf("expected audit records naming the client, got %+v", records)
+	}
+}
+
+func TestAuditRecordsRedactSecrets(t *testing.T) {
+	object := `{"_id": "net-1", "name": "Office", "x_passphrase": "old-secret", "radius": {"secret": "shh"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"meta": {"rc": "ok"}, "data": [` + object + `]}`))
+	}))
+	defer server.Close()
+
+	var records []WriteRecord
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithAuditRecorder(auditRecorderFunc(func(ctx context.Context, record WriteRecord) {
+		records = append(records, record)
+	})))
+
+	settings := map[string]interface{}{
+		"name":              "Office",
+		"x_passphrase":      "new-secret",
+		"wpa_psk":           "new-secret",
+		"radius_profiles":   []interface{}{map[string]interface{}{"auth_password": "pw"}},
+		"private_preshared": map[string]interface{}{"Password": "pw"},
+	}
+	if _, err := client.PatchWiFiNetwork(context.Background(), "default", "net-1", settings); err != nil {
+		t.Fatalf("PatchWiFiNetwork failed: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one audit record, got %d", len(records))
+	}
+
+	record := records[0]
+	want := map[string]interface{}{
+		"name":              "Office",
+		"x_passphrase":      redactedValue,
+		"wpa_psk":           redactedValue,
+		"radius_profiles":   []interface{}{map[string]interface{}{"auth_password": redactedValue}},
+		"private_preshared": map[string]interface{}{"Password": redactedValue},
+	}
+	if !reflect.DeepEqual(record.Request, want) {
+		t.Errorf("unexpected recorded request %v", record.Request)
+	}
+	for _, snapshot := range []map[string]interface{}{record.Before, record.After} {
+		if snapshot["x_passphrase"] != redactedValue || snapshot["radius"].(map[string]interface{})["secret"] != redactedValue || snapshot["name"] != "Office" {
+			t.Errorf("expected snapshot secrets to be redacted, got %v", snapshot)
+		}
+	}
+	// The settings were sent as given and must stay so for the caller
+	if settings["x_passphrase"] != "new-secret" {
+		t.Errorf("redaction changed the caller's settings: %v", settings)
+	}
+}
+
+func TestNormalizeMAC(t *testing.T) {
+	for input, want := range map[string]string{
+		"AA:BB:CC:DD:EE:FF":  "aa:bb:cc:dd:ee:ff",
+		"aa-bb-cc-dd-ee-ff":  "aa:bb:cc:dd:ee:ff",
+		"aabb.ccdd.eeff":     "aa:bb:cc:dd:ee:ff",
+		"AABBCCDDEEFF":       "aa:bb:cc:dd:ee:ff",
+		" aa bb cc dd ee ff": "aa:bb:cc:dd:ee:ff",
+		"aa:bb:cc:dd:ee":     "",
+		"gg:bb:cc:dd:ee:ff":  "",
+	} {
+		got, err := NormalizeMAC(input)
+		if got != want || (err != nil) != (want == "") {
+			t.Errorf("NormalizeMAC(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestAvailableFirmwareIsARead(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"meta": {"rc": "ok"}, "data": [{"device": "U7PG2", "version": "6.7.0", "md5": "abc"}]}`))
+	}))
+	defer server.Close()
+
+	var records []WriteRecord
+	client := NewNetworkClient(server.URL, "test-api-key", false, WithReadOnly(true), WithAuditRecorder(auditRecorderFunc(func(ctx context.Context, record WriteRecord) {
+		records = append(records, record)
+	})))
+
+	firmware, err := client.GetAvailableFirmware(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("expected listing firmware to work on a read-only client, got %v", err)
+	}
+	if len(firmware) != 1 || firmware[0].Device != "U7PG2" || firmware[0].Version != "6.7.0" {
+		t.Errorf("unexpected firmware: %+v", firmware)
+	}
+	if body["cmd"] != "list-available" || len(records) != 0 {
+		t.Errorf("expected an unaudited list-available command, got %v and %d audit records", body, len(records))
+	}
+}
+
+func TestGetDeviceDetailed(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/integration/v1/sites/site-uuid/devices/dev-1"):
+			w.Write([]byte(`{"id": "dev-1", "macAddress": "AA:BB:CC:00:00:01", "features": {"switching": {}, "accessPoint": {}}}`))
+		case strings.HasSuffix(r.URL.Path, "/api/s/default/stat/device/aa:bb:cc:00:00:01"):
+			w.Write([]byte(`{"meta": {"rc": "ok"}, "data": [{
+				"_id": "legacy-1", "mac": "aa:bb:cc:00:00:01", "name": "Lobby", "state": 1, "uptime": 3600,
+				"uplink": {"type": "wire", "uplink_mac": "aa:bb:cc:00:00:09", "speed": 1000},
+				"port_table": [{"port_idx": 1, "up": true, "poe_enable": true, "poe_power": "4.20", "op_mode": "switch"}],
+				"radio_table": [{"name": "wifi0", "radio": "ng", "channel": "auto"}],
+				"system-stats": {"cpu": "12.5", "mem": "40.1"},
+				"temperatures": [{"name": "CPU", "type": "cpu", "value": 52.5}],
+				"config_network": {"type": "dhcp"},
+				"led_override": "default"
+			}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// The integration API takes the site's UUID and the legacy API its name
+	site := NetworkSite{Name: "default", ExternalID: "site-uuid"}
+	client := NewNetworkClient(server.URL, "test-api-key", false)
+	device, err := client.GetDeviceDetailed(context.Background(), site, "dev-1")
+	if err != nil {
+		t.Fatalf("GetDeviceDetailed failed: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("expected the integration and legacy device endpoints to be fetched, got %v", paths)
+	}
+	if len(device.PortTable) != 1 || device.PortTable[0].PoEPower != "4.20" || string(device.PortTable[0].Extra["op_mode"]) != `"switch"` {
+		t.Errorf("unexpected port table: %+v", device.PortTable)
+	}
+	if len(device.RadioTable) != 1 || string(device.RadioTable[0].Channel) != `"auto"` {
+		t.Errorf("unexpected radio table: %+v", device.RadioTable)
+	}
+	if device.Uplink == nil || device.Uplink.Speed != 1000 || device.SystemStats == nil || device.SystemStats.CPU != "12.5" {
+		t.Errorf("unexpected uplink or system stats: %+v %+v", device.Uplink, device.SystemStats)
+	}
+	if len(device.Temperatures) != 1 || device.ConfigNetwork == nil || device.Extra["led_override"] == nil {
+		t.Errorf("unexpected temperatures, config or extra fields: %+v", device)
+	}
+	if strings.Join(device.Features, ",") != "accessPoint,switching" {
+		t.Errorf("expected the integration API features, got %v", device.Features)
+	}
+	if summary := device.Summary(); summary.ID != "legacy-1" || !summary.Connected || summary.Uptime != 3600 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	// A MAC address is looked up in the legacy API directly
+	paths = nil
+	if _, err := client.GetDeviceDetailed(context.Background(), site, "AA:BB:CC:00:00:01"); err != nil || len(paths) != 1 {
+		t.Errorf("expected a single legacy request for a MAC address, got %v %v", paths, err)
+	}
+	if _, err := client.GetDeviceDetailed(context.Background(), site, "dev-2"); !IsNotFound(err) {
+		t.Errorf("expected a not found error for an unknown device, got %v", err)
+	}
+}
+
+func TestTLSPinningAndCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"applicationVersion": "9.0.114"}`))
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+
+	for name, tc := range map[string]struct {
+		opts TLSOptions
+		ok   bool
+	}{
+		"no options":  {TLSOptions{}, false},
+		"pinned":      {TLSOptions{CertSHA256: []string{strings.ToUpper(hex.EncodeToString(sum[:]))}}, true},
+		"wrong pin":   {TLSOptions{CertSHA256: []string{strings.Repeat("ab", sha256.Size)}}, false},
+		"CA file":     {TLSOptions{CAFile: caFile}, true},
+		"CA and pin":  {TLSOptions{CAFile: caFile, CertSHA256: []string{hex.EncodeToString(sum[:])}}, true},
+		"CA and miss": {TLSOptions{CAFile: caFile, CertSHA256: []string{strings.Repeat("ab", sha256.Size)}}, false},
+	} {
+		tlsConfig, err := NewTLSConfig(tc.opts)
+		if err != nil {
+			t.Fatalf("%s: NewTLSConfig failed: %v", name, err)
+		}
+		client := NewNetworkClient(server.URL, "test-api-key", false, WithTLSConfig(tlsConfig),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+		if _, err := client.GetInfo(context.Background()); (err == nil) != tc.ok {
+			t.Errorf("%s: expected success %v, got %v", name, tc.ok, err)
+		}
+	}
+
+	if _, err := NewTLSConfig(TLSOptions{CertSHA256: []string{"not-a-fingerprint"}}); err == nil {
+		t.Error("expected an invalid fingerprint to be rejected")
+	}
+	if _, err := NewTLSConfig(TLSOptions{ClientCertFile: caFile}); err == nil {
+		t.Error("expected a client certificate without a key to be rejected")
+	}
+}
+
+func TestTransportUsesConfiguredProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte(`{"applicationVersion": "9.0.114"}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := ParseProxyURL(proxy.URL)
+	if err != nil {
+		t.Fatalf("ParseProxyURL failed: %v", err)
+	}
+	cfg := DefaultTransportConfig()
+	cfg.Proxy = proxyURL
+	client := NewNetworkClient("http://controller.invalid", "test-api-key", false, WithTransportConfig(cfg))
+
+	if _, err := client.GetInfo(context.Background()); err != nil {
+		t.Fatalf("GetInfo through the proxy failed: %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://controller.invalid/proxy/network/integration/v1/info" {
+		t.Errorf("expected the request to go through the proxy, got %v", proxied)
+	}
+
+	// Without a configured proxy the environment is honoured, even with TLS verification off
+	transport := NewNetworkClient("https://localhost", "test-api-key", true).httpClient.Transport.(*http.Transport)
+	if transport.Proxy == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected the default transport settings to be kept")
+	}
+
+	if _, err := ParseProxyURL("ftp://bastion"); err == nil {
+		t.Error("expected an unsupported proxy scheme to be rejected")
+	}
+}
@@ -0,0 +1,206 @@
+package unifi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ControllerType identifies how the Network application is hosted
+type ControllerType string
+
+// Controller types
+const (
+	// ControllerAuto detects the controller type on the first Authenticate
+	ControllerAuto ControllerType = "auto"
+	// ControllerUniFiOS is a UniFi OS console (UDM, UCG, Cloud Key Gen2+), which serves the Network application under /proxy/network
+	ControllerUniFiOS ControllerType = "unifi-os"
+	// ControllerClassic is a standalone Network application, usually on port 8443, serving its API at the root
+	ControllerClassic ControllerType = "classic"
+)
+
+// unifiOSPrefix is where UniFi OS consoles serve the Network application
+const unifiOSPrefix = "/proxy/network"
+
+// classicPort is the default HTTPS port of a standalone Network application
+const classicPort = "8443"
+
+// DefaultAuthTTL is how long a successful Authenticate probe is trusted
+const DefaultAuthTTL = 5 * time.Minute
+
+// ParseControllerType parses a controller type name; the empty string means auto
+func ParseControllerType(name string) (ControllerType, error) {
+	switch t := ControllerType(strings.ToLower(strings.TrimSpace(name))); t {
+	case "":
+		return ControllerAuto, nil
+	case ControllerAuto, ControllerUniFiOS, ControllerClassic:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown controller type %q (want auto, unifi-os or classic)", name)
+}
+
+// WithControllerType skips detection and assumes the given controller type
+func WithControllerType(controllerType ControllerType) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.controllerType = controllerType
+	}
+}
+
+// WithAuthTTL sets how long a successful Authenticate probe is trusted before it is repeated
+func WithAuthTTL(ttl time.Duration) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.authTTL = ttl
+	}
+}
+
+// BaseURL returns the controller URL the client was created with
+func (nc *NetworkClient) BaseURL() string {
+	return nc.baseURL
+}
+
+// ControllerType returns the detected, or configured, controller type.
+// It is ControllerAuto until Authenticate has succeeded.
+func (nc *NetworkClient) ControllerType() ControllerType {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return nc.controllerType
+}
+
+// networkURL builds the URL of a Network application path for the detected
+// controller type; path is a format string starting with a slash
+func (nc *NetworkClient) networkURL(path string, args ...interface{}) string {
+	nc.mu.RLock()
+	root := nc.root
+	nc.mu.RUnlock()
+	if len(args) > 0 {
+		path = fmt.Sprintf(path, args...)
+	}
+	return root + path
+}
+
+// networkRoot returns the URL under which a controller of the given type serves
+// the Network application. Undetected controllers are assumed to run UniFi OS.
+func networkRoot(baseURL string, controllerType ControllerType) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if controllerType == ControllerClassic {
+		return baseURL
+	}
+	return baseURL + unifiOSPrefix
+}
+
+// Authenticate verifies the API key, or logs in with the session credentials,
+// and detects the controller type. A successful probe is cached for the auth TTL.
+func (nc *NetworkClient) Authenticate(ctx context.Context) error {
+	if nc.apiKey == "" && !nc.sessionAuth() {
+		return fmt.Errorf("no credentials configured: set an API key or a username and password")
+	}
+
+	nc.probeMu.Lock()
+	defer nc.probeMu.Unlock()
+
+	nc.mu.RLock()
+	verified := !nc.verifiedAt.IsZero() && time.Since(nc.verifiedAt) < nc.authTTL
+	nc.mu.RUnlock()
+	if verified {
+		return nil
+	}
+
+	nc.logger.Debug("Verifying Unifi Network credentials")
+	probe := nc.probe
+	if nc.sessionAuth() {
+		probe = nc.loginAny
+	}
+	candidate, err := probe(ctx)
+	if err != nil {
+		return err
+	}
+
+	nc.mu.Lock()
+	nc.controllerType = candidate.controllerType
+	nc.root = candidate.root
+	nc.verifiedAt = time.Now()
+	nc.mu.Unlock()
+
+	nc.logger.WithFields(logrus.Fields{
+		"controller_type": candidate.controllerType,
+		"url":             candidate.root,
+	}).Info("Unifi Network credentials verified")
+	return nil
+}
+
+// invalidateAuth forces the next Authenticate to probe the controller again
+func (nc *NetworkClient) invalidateAuth() {
+	nc.mu.Lock()
+	nc.verifiedAt = time.Time{}
+	nc.mu.Unlock()
+}
+
+type controllerCandidate struct {
+	controllerType ControllerType
+	root           string
+}
+
+// candidates lists where the Network application may be served, most likely first
+func (nc *NetworkClient) candidates() []controllerCandidate {
+	nc.mu.RLock()
+	controllerType := nc.controllerType
+	nc.mu.RUnlock()
+
+	unifiOS := controllerCandidate{ControllerUniFiOS, networkRoot(nc.baseURL, ControllerUniFiOS)}
+	classic := controllerCandidate{ControllerClassic, networkRoot(nc.baseURL, ControllerClassic)}
+
+	switch controllerType {
+	case ControllerUniFiOS:
+		return []controllerCandidate{unifiOS}
+	case ControllerClassic:
+		return []controllerCandidate{classic}
+	}
+
+	candidates := []controllerCandidate{unifiOS, classic}
+	// A standalone application is usually on 8443 even when the URL names no port
+	if u, err := url.Parse(nc.baseURL); err == nil && u.Port() == "" && u.Scheme == "https" {
+		u.Host = u.Hostname() + ":" + classicPort
+		candidates = append(candidates, controllerCandidate{ControllerClassic, networkRoot(u.String(), ControllerClassic)})
+	}
+	return candidates
+}
+
+// probe requests the application info from each candidate and returns the first that answers
+func (nc *NetworkClient) probe(ctx context.Context) (controllerCandidate, error) {
+	var lastErr error
+	for _, candidate := range nc.candidates() {
+		_, err := nc.getBody(ctx, candidate.root+"/integration/v1/info")
+		if err == nil {
+			return candidate, nil
+		}
+		if IsUnauthorized(err) {
+			return controllerCandidate{}, fmt.Errorf("controller rejected the API key: %w", err)
+		}
+		if ctx.Err() != nil {
+			return controllerCandidate{}, ctx.Err()
+		}
+
+		nc.logger.WithError(err).WithField("controller_type", candidate.controllerType).Debug("Controller probe failed")
+		lastErr = err
+	}
+	return controllerCandidate{}, fmt.Errorf("no UniFi Network application found at %s (last error: %v)", nc.baseURL, lastErr)
+}
+
+// observeResponse tracks the session's CSRF token and drops the cached
+// verification when the controller stops accepting the credentials
+func (nc *NetworkClient) observeResponse(resp *http.Response, err error) {
+	if err != nil {
+		return
+	}
+	if nc.sessionAuth() {
+		nc.updateCSRFToken(resp)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		nc.invalidateAuth()
+	}
+}
@@ -0,0 +1,151 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Device commands understood by the controller's device and site managers
+const (
+	DeviceCommandRestart        = "restart"
+	DeviceCommandLocate         = "set-locate"
+	DeviceCommandUnlocate       = "unset-locate"
+	DeviceCommandForceProvision = "force-provision"
+	DeviceCommandUpgrade        = "upgrade"
+	DeviceCommandAdopt          = "adopt"
+	DeviceCommandForget         = "delete-device"
+	DeviceCommandPowerCycle     = "power-cycle"
+)
+
+// restartOptions asks for a reboot rather than a power cycle of the device
+var restartOptions = map[string]interface{}{"reboot_type": "soft"}
+
+// RestartDevice reboots the device with the given MAC address
+func (nc *NetworkClient) RestartDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return nc.deviceCommand(ctx, siteID, "devmgr", DeviceCommandRestart, mac, restartOptions)
+}
+
+// PlanRestartDevice returns the request RestartDevice would send, without sending it
+func (nc *NetworkClient) PlanRestartDevice(siteID, mac string) RequestPlan {
+	return nc.planDeviceCommand(siteID, "devmgr", DeviceCommandRestart, mac, restartOptions)
+}
+
+// LocateDevice starts or stops blinking the LED of the device with the given MAC address
+func (nc *NetworkClient) LocateDevice(ctx context.Context, siteID, mac string, enabled bool) (map[string]interface{}, error) {
+	command := DeviceCommandLocate
+	if !enabled {
+		command = DeviceCommandUnlocate
+	}
+	return nc.deviceCommand(ctx, siteID, "devmgr", command, mac, nil)
+}
+
+// ForceProvisionDevice pushes the current configuration to the device with the given MAC address
+func (nc *NetworkClient) ForceProvisionDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return nc.deviceCommand(ctx, siteID, "devmgr", DeviceCommandForceProvision, mac, nil)
+}
+
+// AdoptDevice adopts the pending device with the given MAC address into the site
+func (nc *NetworkClient) AdoptDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return nc.deviceCommand(ctx, siteID, "devmgr", DeviceCommandAdopt, mac, nil)
+}
+
+// ForgetDevice removes the device with the given MAC address from the site. The
+// device has to be adopted again before the controller manages it.
+func (nc *NetworkClient) ForgetDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return nc.deviceCommand(ctx, siteID, "sitemgr", DeviceCommandForget, mac, nil)
+}
+
+// PlanForgetDevice returns the request ForgetDevice would send, without sending it
+func (nc *NetworkClient) PlanForgetDevice(siteID, mac string) RequestPlan {
+	return nc.planDeviceCommand(siteID, "sitemgr", DeviceCommandForget, mac, nil)
+}
+
+// deviceCommand posts a command to api/s/{site}/cmd/{manager} and returns the
+// controller's acknowledgement, which is usually empty
+func (nc *NetworkClient) deviceCommand(ctx context.Context, siteID, manager, command, mac string, extra map[string]interface{}) (map[string]interface{}, error) {
+	nc.logger.WithFields(logrus.Fields{
+		"site_id": siteID,
+		"command": command,
+		"mac":     mac,
+	}).Debug("Sending device command")
+
+	plan := nc.planDeviceCommand(siteID, manager, command, mac, extra)
+	return nc.makePostRequest(ctx, plan.URL, plan.Body)
+}
+
+// planDeviceCommand returns the request deviceCommand sends
+func (nc *NetworkClient) planDeviceCommand(siteID, manager, command, mac string, extra map[string]interface{}) RequestPlan {
+	return RequestPlan{
+		Method: "POST",
+		URL:    nc.networkURL("/api/s/%s/cmd/%s", siteID, manager),
+		Body:   commandPayload(command, mac, extra),
+	}
+}
+
+// commandPayload builds the body of a device command
+func commandPayload(command, mac string, extra map[string]interface{}) map[string]interface{} {
+	payload := map[string]interface{}{"cmd": command, "mac": strings.ToLower(mac)}
+	if command == DeviceCommandForget {
+		// Forgetting takes a list of devices
+		delete(payload, "mac")
+		payload["macs"] = []string{strings.ToLower(mac)}
+	}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	return payload
+}
+
+// integrationDevice is the part of an integration API device record needed to
+// look the device up in the legacy API
+type integrationDevice struct {
+	MAC        string          `json:"mac"`
+	MACAddress string          `json:"macAddress"`
+	Features   json.RawMessage `json:"features"`
+}
+
+func (d *integrationDevice) macAddress() string {
+	if d.MACAddress != "" {
+		return d.MACAddress
+	}
+	return d.MAC
+}
+
+// features returns the names of the device's features, which the controller
+// sends either as a list or as an object keyed by feature
+func (d *integrationDevice) features() []string {
+	var names []string
+	if json.Unmarshal(d.Features, &names) == nil {
+		return names
+	}
+	var keyed map[string]json.RawMessage
+	if json.Unmarshal(d.Features, &keyed) != nil {
+		return nil
+	}
+	for name := range keyed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getIntegrationDevice fetches a single device from the integration API, which
+// returns the object itself rather than a data envelope
+func (nc *NetworkClient) getIntegrationDevice(ctx context.Context, siteID, deviceID string) (*integrationDevice, error) {
+	url := nc.networkURL("/integration/v1/sites/%s/devices/%s", siteID, deviceID)
+	body, err := nc.getBody(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var device integrationDevice
+	if err := json.Unmarshal(body, &device); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &device, nil
+}
@@ -0,0 +1,172 @@
+package unifi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxErrorBody bounds how much of an unparseable error body is kept in an APIError
+const maxErrorBody = 512
+
+// APIError is returned when the controller answers a request with an error
+type APIError struct {
+	StatusCode int    // HTTP status code
+	Method     string // HTTP method of the failed request
+	Path       string // URL path of the failed request
+	Code       string // controller error code, e.g. api.err.InvalidPayload
+	Message    string // controller error message, or the raw body when it has none
+	RequestID  string // controller request ID, when one was returned
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "request failed with status %d (%s %s)", e.StatusCode, e.Method, e.Path)
+	switch {
+	case e.Code != "" && e.Message != "" && e.Message != e.Code:
+		fmt.Fprintf(&b, ": %s: %s", e.Code, e.Message)
+	case e.Code != "":
+		fmt.Fprintf(&b, ": %s", e.Code)
+	case e.Message != "":
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request ID %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+// errorEnvelope covers the error bodies of both API generations:
+// legacy api/s/... endpoints answer {"meta":{"rc":"error","msg":"api.err.X"}},
+// integration/v1 endpoints answer {"statusCode":400,"code":"...","message":"...","requestId":"..."}
+type errorEnvelope struct {
+	Meta struct {
+		RC  string `json:"rc"`
+		Msg string `json:"msg"`
+	} `json:"meta"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+}
+
+// newAPIError builds an APIError from a failed response and its body
+func newAPIError(req *http.Request, statusCode int, header http.Header, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RequestID:  header.Get("X-Request-Id"),
+	}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err == nil {
+		apiErr.Code = envelope.Code
+		apiErr.Message = envelope.Message
+		if envelope.Meta.Msg != "" {
+			apiErr.Code = envelope.Meta.Msg
+		}
+		if envelope.RequestID != "" {
+			apiErr.RequestID = envelope.RequestID
+		}
+	}
+	if apiErr.Code == "" && apiErr.Message == "" {
+		message := strings.TrimSpace(string(body))
+		if len(message) > maxErrorBody {
+			message = message[:maxErrorBody] + "..."
+		}
+		apiErr.Message = message
+	}
+	return apiErr
+}
+
+// legacyError reports the error a legacy endpoint returned with a 200 status, if any
+func legacyError(req *http.Request, statusCode int, header http.Header, body []byte) *APIError {
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Meta.RC != "error" {
+		return nil
+	}
+	apiErr := newAPIError(req, statusCode, header, body)
+	if apiErr.StatusCode < 400 {
+		apiErr.StatusCode = http.StatusBadRequest
+	}
+	return apiErr
+}
+
+// notFoundError reports an object missing from an otherwise successful response
+func notFoundError(method, path, what string) *APIError {
+	return &APIError{
+		StatusCode: http.StatusNotFound,
+		Method:     method,
+		Path:       path,
+		Code:       "api.err.ObjectNotFound",
+		Message:    what + " not found",
+	}
+}
+
+// IsNotFound reports whether err means the requested object does not exist
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusNotFound || strings.HasSuffix(apiErr.Code, "NotFound")
+}
+
+// IsSiteNotFound reports whether the controller does not know the site a request
+// was addressed to: legacy endpoints answer api.err.NoSiteContext, integration
+// endpoints a 404 about the site
+func IsSiteNotFound(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code == "api.err.NoSiteContext" {
+		return true
+	}
+	return apiErr.StatusCode == http.StatusNotFound && strings.Contains(strings.ToLower(apiErr.Code+" "+apiErr.Message), "site")
+}
+
+// IsUnauthorized reports whether the controller rejected the credentials or their permissions
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	return apiErr.Code == "api.err.LoginRequired" || apiErr.Code == "api.err.NoPermission"
+}
+
+// IsValidation reports whether the controller rejected the request as invalid
+func IsValidation(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return true
+	}
+	return strings.HasPrefix(apiErr.Code, "api.err.Invalid")
+}
+
+// IsRateLimited reports whether the controller asked the client to slow down
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// pathOf returns the path of rawURL, or rawURL itself when it cannot be parsed
+func pathOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
@@ -0,0 +1,33 @@
+package unifi
+
+import (
+	"context"
+)
+
+// GetAvailableFirmware lists the firmware releases the controller offers for
+// the device models it knows about
+func (nc *NetworkClient) GetAvailableFirmware(ctx context.Context, siteID string) ([]NetworkFirmware, error) {
+	nc.logger.WithField("site_id", siteID).Debug("Fetching available firmware")
+
+	url := nc.networkURL("/api/s/%s/cmd/firmware", siteID)
+	data, err := nc.queryData(ctx, url, map[string]interface{}{"cmd": "list-available"})
+	if err != nil {
+		return nil, err
+	}
+	return decodeList[NetworkFirmware](data)
+}
+
+// GetDeviceFirmware retrieves the running firmware version of every adopted
+// device of a site and whether the controller has a newer one for it
+func (nc *NetworkClient) GetDeviceFirmware(ctx context.Context, siteID string) ([]NetworkDeviceFirmware, error) {
+	nc.logger.WithField("site_id", siteID).Debug("Fetching device firmware")
+
+	url := nc.networkURL("/api/s/%s/stat/device", siteID)
+	return getList[NetworkDeviceFirmware](ctx, nc, url)
+}
+
+// UpgradeDevice upgrades the device with the given MAC address to the newest
+// firmware the controller offers for it. The device reboots while upgrading.
+func (nc *NetworkClient) UpgradeDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return nc.deviceCommand(ctx, siteID, "devmgr", DeviceCommandUpgrade, mac, nil)
+}
@@ -0,0 +1,771 @@
+package unifi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Every model keeps the fields the controller sends that it does not declare in
+// Extra, and writes them back out when marshalled, so no data is lost between
+// controller versions. Objects are identified by "id" in the integration API and
+// by "_id" in the legacy API; ID is filled from whichever of the two is present.
+
+// NetworkFirewallZone represents a firewall zone
+type NetworkFirewallZone struct {
+	ID         string                     `json:"id"`
+	Name       string                     `json:"name"`
+	NetworkIDs []string                   `json:"networkIds,omitempty"`
+	Metadata   *NetworkObjectMetadata     `json:"metadata,omitempty"`
+	Extra      map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkObjectMetadata describes where a configuration object came from
+type NetworkObjectMetadata struct {
+	Origin string `json:"origin,omitempty"`
+}
+
+// NetworkACLRule represents an ACL rule
+type NetworkACLRule struct {
+	ID                string                     `json:"id"`
+	Type              string                     `json:"type,omitempty"`
+	Name              string                     `json:"name"`
+	Description       string                     `json:"description,omitempty"`
+	Enabled           bool                       `json:"enabled"`
+	Action            string                     `json:"action"`
+	Index             int                        `json:"index"`
+	SourceFilter      json.RawMessage            `json:"sourceFilter,omitempty"`
+	DestinationFilter json.RawMessage            `json:"destinationFilter,omitempty"`
+	Metadata          *NetworkObjectMetadata     `json:"metadata,omitempty"`
+	Extra             map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkHotspotVoucher represents a hotspot voucher
+type NetworkHotspotVoucher struct {
+	ID                   string                     `json:"id"`
+	Name                 string                     `json:"name"`
+	Code                 string                     `json:"code"`
+	CreatedAt            string                     `json:"createdAt,omitempty"`
+	ActivatedAt          string                     `json:"activatedAt,omitempty"`
+	ExpiresAt            string                     `json:"expiresAt,omitempty"`
+	Expired              bool                       `json:"expired"`
+	TimeLimitMinutes     int64                      `json:"timeLimitMinutes,omitempty"`
+	AuthorizedGuestLimit int                        `json:"authorizedGuestLimit,omitempty"`
+	AuthorizedGuestCount int                        `json:"authorizedGuestCount"`
+	DataUsageLimitMBytes int64                      `json:"dataUsageLimitMBytes,omitempty"`
+	RxRateLimitKbps      int64                      `json:"rxRateLimitKbps,omitempty"`
+	TxRateLimitKbps      int64                      `json:"txRateLimitKbps,omitempty"`
+	Extra                map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkTrafficRule represents a traffic matching rule
+type NetworkTrafficRule struct {
+	ID             string                     `json:"_id"`
+	Description    string                     `json:"description"`
+	Enabled        bool                       `json:"enabled"`
+	Action         string                     `json:"action"`
+	MatchingTarget string                     `json:"matching_target,omitempty"`
+	TargetDevices  json.RawMessage            `json:"target_devices,omitempty"`
+	Schedule       json.RawMessage            `json:"schedule,omitempty"`
+	SiteID         string                     `json:"site_id,omitempty"`
+	Extra          map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkVPNTunnel represents a VPN site-to-site tunnel configuration
+type NetworkVPNTunnel struct {
+	ID      string                     `json:"_id"`
+	Name    string                     `json:"name"`
+	Enabled bool                       `json:"enabled"`
+	VPNType string                     `json:"vpn_type,omitempty"`
+	SiteID  string                     `json:"site_id,omitempty"`
+	Extra   map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkWANConfig represents a WAN interface configuration
+type NetworkWANConfig struct {
+	ID              string                     `json:"_id"`
+	Name            string                     `json:"name"`
+	Enabled         bool                       `json:"enabled"`
+	WANType         string                     `json:"wan_type,omitempty"`
+	WANNetworkGroup string                     `json:"wan_networkgroup,omitempty"`
+	SiteID          string                     `json:"site_id,omitempty"`
+	Extra           map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkRADIUSProfile represents a RADIUS server profile
+type NetworkRADIUSProfile struct {
+	ID                    string                     `json:"_id"`
+	Name                  string                     `json:"name"`
+	AuthServers           []NetworkRADIUSServer      `json:"auth_servers,omitempty"`
+	AcctServers           []NetworkRADIUSServer      `json:"acct_servers,omitempty"`
+	AccountingEnabled     bool                       `json:"accounting_enabled"`
+	VLANEnabled           bool                       `json:"vlan_enabled"`
+	UseUSGAuthServer      bool                       `json:"use_usg_auth_server,omitempty"`
+	InterimUpdateInterval int                        `json:"interim_update_interval,omitempty"`
+	SiteID                string                     `json:"site_id,omitempty"`
+	Extra                 map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkRADIUSServer is a server entry of a RADIUS profile. The shared secret
+// (x_secret) is deliberately not declared, but is kept in Extra.
+type NetworkRADIUSServer struct {
+	IP    string                     `json:"ip"`
+	Port  int                        `json:"port"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDeviceTag represents a device tag
+type NetworkDeviceTag struct {
+	ID          string                     `json:"_id"`
+	Name        string                     `json:"name"`
+	MemberTable []string                   `json:"member_table"`
+	SiteID      string                     `json:"site_id,omitempty"`
+	Extra       map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkWiFiBroadcast represents a WiFi broadcast (SSID)
+type NetworkWiFiBroadcast struct {
+	ID                    string                     `json:"id"`
+	Type                  string                     `json:"type,omitempty"`
+	Name                  string                     `json:"name"`
+	Enabled               bool                       `json:"enabled"`
+	Network               json.RawMessage            `json:"network,omitempty"`
+	SecurityConfiguration json.RawMessage            `json:"securityConfiguration,omitempty"`
+	Metadata              *NetworkObjectMetadata     `json:"metadata,omitempty"`
+	Extra                 map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkConnectedClient represents a client as listed by the integration API
+type NetworkConnectedClient struct {
+	ID             string                     `json:"id"`
+	Type           string                     `json:"type"`
+	Name           string                     `json:"name"`
+	ConnectedAt    string                     `json:"connectedAt,omitempty"`
+	IPAddress      string                     `json:"ipAddress,omitempty"`
+	MACAddress     string                     `json:"macAddress"`
+	UplinkDeviceID string                     `json:"uplinkDeviceId,omitempty"`
+	Extra          map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkHealthSubsystem represents the health of one site subsystem (wan, lan, wlan, vpn, ...)
+type NetworkHealthSubsystem struct {
+	Subsystem       string                     `json:"subsystem"`
+	Status          string                     `json:"status"`
+	NumUser         int                        `json:"num_user,omitempty"`
+	NumGuest        int                        `json:"num_guest,omitempty"`
+	NumAP           int                        `json:"num_ap,omitempty"`
+	NumSwitches     int                        `json:"num_sw,omitempty"`
+	NumGateways     int                        `json:"num_gw,omitempty"`
+	NumAdopted      int                        `json:"num_adopted,omitempty"`
+	NumDisconnected int                        `json:"num_disconnected,omitempty"`
+	NumPending      int                        `json:"num_pending,omitempty"`
+	WANIP           string                     `json:"wan_ip,omitempty"`
+	TxBytesRate     float64                    `json:"tx_bytes-r,omitempty"`
+	RxBytesRate     float64                    `json:"rx_bytes-r,omitempty"`
+	Extra           map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkApplicationInfo describes the UniFi Network application
+type NetworkApplicationInfo struct {
+	ApplicationVersion string                     `json:"applicationVersion"`
+	Extra              map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkPendingDevice represents a device waiting to be adopted
+type NetworkPendingDevice struct {
+	MACAddress        string                     `json:"macAddress"`
+	IPAddress         string                     `json:"ipAddress,omitempty"`
+	Model             string                     `json:"model"`
+	State             string                     `json:"state,omitempty"`
+	Supported         bool                       `json:"supported"`
+	FirmwareVersion   string                     `json:"firmwareVersion,omitempty"`
+	FirmwareUpdatable bool                       `json:"firmwareUpdatable"`
+	Features          []string                   `json:"features,omitempty"`
+	Extra             map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDPICategory represents a DPI traffic category
+type NetworkDPICategory struct {
+	ID    int                        `json:"id"`
+	Name  string                     `json:"name"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDPIApplication represents an application recognised by DPI
+type NetworkDPIApplication struct {
+	ID    int                        `json:"id"`
+	Name  string                     `json:"name"`
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkConfig represents a network (LAN, VLAN, WAN or VPN) from the legacy networkconf collection
+type NetworkConfig struct {
+	ID           string                     `json:"_id"`
+	Name         string                     `json:"name"`
+	Purpose      string                     `json:"purpose"`
+	Enabled      bool                       `json:"enabled"`
+	VLANEnabled  bool                       `json:"vlan_enabled,omitempty"`
+	VLAN         int                        `json:"vlan,omitempty"`
+	IPSubnet     string                     `json:"ip_subnet,omitempty"`
+	NetworkGroup string                     `json:"networkgroup,omitempty"`
+	DHCPDEnabled bool                       `json:"dhcpd_enabled,omitempty"`
+	SiteID       string                     `json:"site_id,omitempty"`
+	Extra        map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkFirmware is a firmware release the controller offers for a device model
+type NetworkFirmware struct {
+	ID       string                     `json:"_id,omitempty"`
+	Device   string                     `json:"device"` // model code, e.g. U7PG2
+	Version  string                     `json:"version"`
+	Platform string                     `json:"platform,omitempty"`
+	URL      string                     `json:"url,omitempty"`
+	MD5      string                     `json:"md5,omitempty"`
+	Size     int64                      `json:"size,omitempty"`
+	Extra    map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDeviceFirmware is the firmware state of an adopted device from the legacy stat/device collection
+type NetworkDeviceFirmware struct {
+	ID                string                     `json:"_id"`
+	MAC               string                     `json:"mac"`
+	Name              string                     `json:"name"`
+	Model             string                     `json:"model"`
+	Version           string                     `json:"version"`
+	Upgradable        bool                       `json:"upgradable"`
+	UpgradeToFirmware string                     `json:"upgrade_to_firmware,omitempty"`
+	State             int                        `json:"state"`
+	Extra             map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDeviceDetail is the full record of one adopted device from the legacy
+// stat/device/{mac} endpoint, with the feature set reported by the integration API
+type NetworkDeviceDetail struct {
+	ID            string                      `json:"_id"`
+	MAC           string                      `json:"mac"`
+	Name          string                      `json:"name"`
+	Type          string                      `json:"type"`
+	Model         string                      `json:"model"`
+	Serial        string                      `json:"serial,omitempty"`
+	Version       string                      `json:"version"`
+	IP            string                      `json:"ip"`
+	State         int                         `json:"state"`
+	Adopted       bool                        `json:"adopted"`
+	Uptime        int64                       `json:"uptime"`
+	LastSeen      int64                       `json:"last_seen"`
+	Features      []string                    `json:"features,omitempty"`
+	Uplink        *NetworkDeviceUplink        `json:"uplink,omitempty"`
+	PortTable     []NetworkDevicePort         `json:"port_table,omitempty"`
+	RadioTable    []NetworkDeviceRadio        `json:"radio_table,omitempty"`
+	SystemStats   *NetworkDeviceSystemStats   `json:"system-stats,omitempty"`
+	Temperatures  []NetworkDeviceTemperature  `json:"temperatures,omitempty"`
+	ConfigNetwork *NetworkDeviceConfigNetwork `json:"config_network,omitempty"`
+	PortOverrides []NetworkPortOverride       `json:"port_overrides,omitempty"`
+	Extra         map[string]json.RawMessage  `json:"-"`
+}
+
+// Summary returns the compact view of the device that the device list reports
+func (d *NetworkDeviceDetail) Summary() NetworkDevice {
+	return NetworkDevice{
+		ID:        d.ID,
+		Name:      d.Name,
+		Type:      d.Type,
+		Model:     d.Model,
+		MAC:       d.MAC,
+		IP:        d.IP,
+		Connected: d.State == DeviceStateConnected,
+		LastSeen:  d.LastSeen,
+		Uptime:    d.Uptime,
+	}
+}
+
+// DeviceStateConnected is the legacy device state of a connected device
+const DeviceStateConnected = 1
+
+// NetworkDeviceUplink describes how a device connects to the rest of the network
+type NetworkDeviceUplink struct {
+	Type             string `json:"type,omitempty"` // wire or wireless
+	UplinkMAC        string `json:"uplink_mac,omitempty"`
+	UplinkRemotePort int    `json:"uplink_remote_port,omitempty"`
+	PortIdx          int    `json:"port_idx,omitempty"`
+	Speed            int    `json:"speed,omitempty"` // Mbps
+	FullDuplex       bool   `json:"full_duplex,omitempty"`
+	RxBytes          int64  `json:"rx_bytes,omitempty"`
+	TxBytes          int64  `json:"tx_bytes,omitempty"`
+}
+
+// NetworkDevicePort is one entry of a device's port table
+type NetworkDevicePort struct {
+	PortIdx    int                        `json:"port_idx"`
+	Name       string                     `json:"name"`
+	Enable     bool                       `json:"enable"`
+	Up         bool                       `json:"up"`
+	Speed      int                        `json:"speed,omitempty"` // Mbps
+	FullDuplex bool                       `json:"full_duplex,omitempty"`
+	Media      string                     `json:"media,omitempty"`
+	IsUplink   bool                       `json:"is_uplink,omitempty"`
+	PortconfID string                     `json:"portconf_id,omitempty"`
+	PoECaps    int                        `json:"poe_caps,omitempty"`
+	PoEEnable  bool                       `json:"poe_enable,omitempty"`
+	PoEMode    string                     `json:"poe_mode,omitempty"`
+	PoEPower   string                     `json:"poe_power,omitempty"` // watts
+	PoEVoltage string                     `json:"poe_voltage,omitempty"`
+	PoECurrent string                     `json:"poe_current,omitempty"` // milliamps
+	STPState   string                     `json:"stp_state,omitempty"`
+	RxBytes    int64                      `json:"rx_bytes,omitempty"`
+	TxBytes    int64                      `json:"tx_bytes,omitempty"`
+	Extra      map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDeviceRadio is one entry of an access point's radio table
+type NetworkDeviceRadio struct {
+	Name        string                     `json:"name"`
+	Radio       string                     `json:"radio"`             // ng (2.4 GHz), na (5 GHz), 6e (6 GHz)
+	Channel     json.RawMessage            `json:"channel,omitempty"` // number or "auto"
+	HT          string                     `json:"ht,omitempty"`      // channel width in MHz
+	TxPowerMode string                     `json:"tx_power_mode,omitempty"`
+	MinTxPower  int                        `json:"min_txpower,omitempty"`
+	MaxTxPower  int                        `json:"max_txpower,omitempty"`
+	NSS         int                        `json:"nss,omitempty"`
+	Extra       map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDeviceSystemStats is the latest resource usage of a device. The
+// controller reports the values as strings.
+type NetworkDeviceSystemStats struct {
+	CPU    string `json:"cpu,omitempty"` // percent
+	Mem    string `json:"mem,omitempty"` // percent
+	Uptime string `json:"uptime,omitempty"`
+}
+
+// NetworkDeviceTemperature is the reading of one of a device's temperature sensors
+type NetworkDeviceTemperature struct {
+	Name  string  `json:"name"`
+	Type  string  `json:"type,omitempty"`
+	Value float64 `json:"value"` // degrees Celsius
+}
+
+// NetworkPortOverride is the per-port configuration of a switch that replaces
+// the settings of the port's profile
+type NetworkPortOverride struct {
+	PortIdx             int                        `json:"port_idx"`
+	Name                string                     `json:"name,omitempty"`
+	PortconfID          string                     `json:"portconf_id,omitempty"`
+	NativeNetworkconfID string                     `json:"native_networkconf_id,omitempty"`
+	Forward             string                     `json:"forward,omitempty"` // all, native, customize or disabled
+	PoEMode             string                     `json:"poe_mode,omitempty"`
+	Extra               map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkPortProfile is a switch port profile from the legacy portconf collection
+type NetworkPortProfile struct {
+	ID                     string                     `json:"_id"`
+	Name                   string                     `json:"name"`
+	Forward                string                     `json:"forward,omitempty"`
+	NativeNetworkconfID    string                     `json:"native_networkconf_id,omitempty"`
+	TaggedVLANMgmt         string                     `json:"tagged_vlan_mgmt,omitempty"` // auto, block_all or custom
+	ExcludedNetworkconfIDs []string                   `json:"excluded_networkconf_ids,omitempty"`
+	PoEMode                string                     `json:"poe_mode,omitempty"`
+	Isolation              bool                       `json:"isolation,omitempty"`
+	Autoneg                bool                       `json:"autoneg,omitempty"`
+	Speed                  int                        `json:"speed,omitempty"` // Mbps when autoneg is off
+	SiteID                 string                     `json:"site_id,omitempty"`
+	Extra                  map[string]json.RawMessage `json:"-"`
+}
+
+// NetworkDeviceConfigNetwork is the management IP configuration of a device
+type NetworkDeviceConfigNetwork struct {
+	Type    string `json:"type,omitempty"` // dhcp or static
+	IP      string `json:"ip,omitempty"`
+	Netmask string `json:"netmask,omitempty"`
+	Gateway string `json:"gateway,omitempty"`
+	DNS1    string `json:"dns1,omitempty"`
+	DNS2    string `json:"dns2,omitempty"`
+}
+
+func (m *NetworkDevice) UnmarshalJSON(data []byte) error {
+	type plain NetworkDevice
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDevice) MarshalJSON() ([]byte, error) {
+	type plain NetworkDevice
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkSite) UnmarshalJSON(data []byte) error {
+	type plain NetworkSite
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkSite) MarshalJSON() ([]byte, error) {
+	type plain NetworkSite
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkWiFiNetwork) UnmarshalJSON(data []byte) error {
+	type plain NetworkWiFiNetwork
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkWiFiNetwork) MarshalJSON() ([]byte, error) {
+	type plain NetworkWiFiNetwork
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkClientDevice) UnmarshalJSON(data []byte) error {
+	type plain NetworkClientDevice
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkClientDevice) MarshalJSON() ([]byte, error) {
+	type plain NetworkClientDevice
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkVPNServer) UnmarshalJSON(data []byte) error {
+	type plain NetworkVPNServer
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkVPNServer) MarshalJSON() ([]byte, error) {
+	type plain NetworkVPNServer
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkFirewallZone) UnmarshalJSON(data []byte) error {
+	type plain NetworkFirewallZone
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkFirewallZone) MarshalJSON() ([]byte, error) {
+	type plain NetworkFirewallZone
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkACLRule) UnmarshalJSON(data []byte) error {
+	type plain NetworkACLRule
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkACLRule) MarshalJSON() ([]byte, error) {
+	type plain NetworkACLRule
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkHotspotVoucher) UnmarshalJSON(data []byte) error {
+	type plain NetworkHotspotVoucher
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkHotspotVoucher) MarshalJSON() ([]byte, error) {
+	type plain NetworkHotspotVoucher
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkTrafficRule) UnmarshalJSON(data []byte) error {
+	type plain NetworkTrafficRule
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkTrafficRule) MarshalJSON() ([]byte, error) {
+	type plain NetworkTrafficRule
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkVPNTunnel) UnmarshalJSON(data []byte) error {
+	type plain NetworkVPNTunnel
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkVPNTunnel) MarshalJSON() ([]byte, error) {
+	type plain NetworkVPNTunnel
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkWANConfig) UnmarshalJSON(data []byte) error {
+	type plain NetworkWANConfig
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkWANConfig) MarshalJSON() ([]byte, error) {
+	type plain NetworkWANConfig
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkRADIUSProfile) UnmarshalJSON(data []byte) error {
+	type plain NetworkRADIUSProfile
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkRADIUSProfile) MarshalJSON() ([]byte, error) {
+	type plain NetworkRADIUSProfile
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkRADIUSServer) UnmarshalJSON(data []byte) error {
+	type plain NetworkRADIUSServer
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkRADIUSServer) MarshalJSON() ([]byte, error) {
+	type plain NetworkRADIUSServer
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkDeviceTag) UnmarshalJSON(data []byte) error {
+	type plain NetworkDeviceTag
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDeviceTag) MarshalJSON() ([]byte, error) {
+	type plain NetworkDeviceTag
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkWiFiBroadcast) UnmarshalJSON(data []byte) error {
+	type plain NetworkWiFiBroadcast
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkWiFiBroadcast) MarshalJSON() ([]byte, error) {
+	type plain NetworkWiFiBroadcast
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkConnectedClient) UnmarshalJSON(data []byte) error {
+	type plain NetworkConnectedClient
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkConnectedClient) MarshalJSON() ([]byte, error) {
+	type plain NetworkConnectedClient
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkHealthSubsystem) UnmarshalJSON(data []byte) error {
+	type plain NetworkHealthSubsystem
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkHealthSubsystem) MarshalJSON() ([]byte, error) {
+	type plain NetworkHealthSubsystem
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkApplicationInfo) UnmarshalJSON(data []byte) error {
+	type plain NetworkApplicationInfo
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkApplicationInfo) MarshalJSON() ([]byte, error) {
+	type plain NetworkApplicationInfo
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkPendingDevice) UnmarshalJSON(data []byte) error {
+	type plain NetworkPendingDevice
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkPendingDevice) MarshalJSON() ([]byte, error) {
+	type plain NetworkPendingDevice
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkDPICategory) UnmarshalJSON(data []byte) error {
+	type plain NetworkDPICategory
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDPICategory) MarshalJSON() ([]byte, error) {
+	type plain NetworkDPICategory
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkDPIApplication) UnmarshalJSON(data []byte) error {
+	type plain NetworkDPIApplication
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDPIApplication) MarshalJSON() ([]byte, error) {
+	type plain NetworkDPIApplication
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkConfig) UnmarshalJSON(data []byte) error {
+	type plain NetworkConfig
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkConfig) MarshalJSON() ([]byte, error) {
+	type plain NetworkConfig
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkFirmware) UnmarshalJSON(data []byte) error {
+	type plain NetworkFirmware
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkFirmware) MarshalJSON() ([]byte, error) {
+	type plain NetworkFirmware
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkDeviceFirmware) UnmarshalJSON(data []byte) error {
+	type plain NetworkDeviceFirmware
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDeviceFirmware) MarshalJSON() ([]byte, error) {
+	type plain NetworkDeviceFirmware
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkDeviceDetail) UnmarshalJSON(data []byte) error {
+	type plain NetworkDeviceDetail
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDeviceDetail) MarshalJSON() ([]byte, error) {
+	type plain NetworkDeviceDetail
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkDevicePort) UnmarshalJSON(data []byte) error {
+	type plain NetworkDevicePort
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDevicePort) MarshalJSON() ([]byte, error) {
+	type plain NetworkDevicePort
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkDeviceRadio) UnmarshalJSON(data []byte) error {
+	type plain NetworkDeviceRadio
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkDeviceRadio) MarshalJSON() ([]byte, error) {
+	type plain NetworkDeviceRadio
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkPortOverride) UnmarshalJSON(data []byte) error {
+	type plain NetworkPortOverride
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkPortOverride) MarshalJSON() ([]byte, error) {
+	type plain NetworkPortOverride
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+func (m *NetworkPortProfile) UnmarshalJSON(data []byte) error {
+	type plain NetworkPortProfile
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+func (m NetworkPortProfile) MarshalJSON() ([]byte, error) {
+	type plain NetworkPortProfile
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+// ToMap converts a model into a generic JSON object, including its Extra fields
+func ToMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// unmarshalWithExtra decodes data into the struct pointed to by v and stores the
+// fields v does not declare in extra
+func unmarshalWithExtra(data []byte, v interface{}, extra *map[string]json.RawMessage) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	for name := range jsonFieldNames(rv.Type()) {
+		delete(all, name)
+	}
+
+	// Fall back to the other API's identifier field
+	if id := rv.FieldByName("ID"); id.IsValid() && id.Kind() == reflect.String && id.String() == "" {
+		for _, key := range []string{"id", "_id"} {
+			var value string
+			if raw, ok := all[key]; ok && json.Unmarshal(raw, &value) == nil && value != "" {
+				id.SetString(value)
+				break
+			}
+		}
+	}
+
+	*extra = nil
+	if len(all) > 0 {
+		*extra = all
+	}
+	return nil
+}
+
+// marshalWithExtra encodes v and merges in the extra fields it does not declare
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		if _, declared := all[key]; !declared {
+			all[key] = value
+		}
+	}
+	return json.Marshal(all)
+}
+
+var jsonFieldCache sync.Map // reflect.Type -> map[string]bool
+
+// jsonFieldNames returns the JSON names of the fields declared by struct type t
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if names, ok := jsonFieldCache.Load(t); ok {
+		return names.(map[string]bool)
+	}
+
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+
+	jsonFieldCache.Store(t, names)
+	return names
+}
@@ -0,0 +1,41 @@
+package unifi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestModelKeepsUnknownFields(t *testing.T) {
+	input := `{"_id":"z1","name":"Internal","networkIds":["n1"],"zone_key":"internal","default_zone":true}`
+
+	var zone NetworkFirewallZone
+	if err := json.Unmarshal([]byte(input), &zone); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if zone.ID != "z1" {
+		t.Errorf("expected ID from legacy _id, got %q", zone.ID)
+	}
+	if zone.Name != "Internal" || len(zone.NetworkIDs) != 1 {
+		t.Errorf("declared fields not decoded: %+v", zone)
+	}
+	if string(zone.Extra["zone_key"]) != `"internal"` || string(zone.Extra["default_zone"]) != "true" {
+		t.Errorf("unknown fields not kept in Extra: %v", zone.Extra)
+	}
+	if _, ok := zone.Extra["name"]; ok {
+		t.Error("declared field duplicated in Extra")
+	}
+
+	out, err := json.Marshal(zone)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var roundTrip map[string]interface{}
+	if err := json.Unmarshal(out, &roundTrip); err != nil {
+		t.Fatalf("failed to decode marshalled zone: %v", err)
+	}
+	for _, key := range []string{"id", "_id", "name", "networkIds", "zone_key", "default_zone"} {
+		if _, ok := roundTrip[key]; !ok {
+			t.Errorf("marshalled zone is missing %q: %s", key, out)
+		}
+	}
+}
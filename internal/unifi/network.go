@@ -5,59 +5,105 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// ErrReadOnly is returned when a write request is attempted on a read-only client
+var ErrReadOnly = errors.New("client is read-only: write requests are disabled")
+
 // NetworkClient handles communication with Unifi Network API
 type NetworkClient struct {
 	baseURL    string
 	apiKey     string
+	readOnly   bool
+	auditor    AuditRecorder
+	retry      RetryPolicy
+	tlsConfig  *tls.Config
+	transport  TransportConfig
 	httpClient *http.Client
 	logger     *logrus.Entry
+
+	// Controller detection, see controller.go
+	controllerType ControllerType
+	authTTL        time.Duration
+	probeMu        sync.Mutex // serializes probes
+	mu             sync.RWMutex
+	root           string // baseURL plus the Network application prefix
+	verifiedAt     time.Time
+
+	// Session authentication, see session.go
+	username   string
+	password   string
+	loginMu    sync.Mutex // serializes logins after 401s
+	csrfToken  string     // guarded by mu
+	sessionGen int        // incremented on every login, guarded by mu
+
+	// Response cache, see cache.go
+	cache     ResponseCache
+	cacheTTLs map[string]time.Duration
+	cacheGen  atomic.Uint64 // incremented on every invalidation
+}
+
+// ClientOption configures optional NetworkClient behaviour
+type ClientOption func(*NetworkClient)
+
+// WithReadOnly makes the client refuse to send any write request
+func WithReadOnly(readOnly bool) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.readOnly = readOnly
+	}
 }
 
 // NetworkDevice represents a device in Unifi Network
 type NetworkDevice struct {
-	ID             string `json:"_id"`
-	Name           string `json:"name"`
-	Type           string `json:"type"`
-	Model          string `json:"model"`
-	MAC            string `json:"mac"`
-	IP             string `json:"ip"`
-	Connected      bool   `json:"connected"`
-	LastSeen       int64  `json:"last_seen"`
-	Uptime         int64  `json:"uptime"`
-	SignalStrength int    `json:"signal,omitempty"`
+	ID             string                     `json:"_id"`
+	Name           string                     `json:"name"`
+	Type           string                     `json:"type"`
+	Model          string                     `json:"model"`
+	MAC            string                     `json:"mac"`
+	IP             string                     `json:"ip"`
+	Connected      bool                       `json:"connected"`
+	LastSeen       int64                      `json:"last_seen"`
+	Uptime         int64                      `json:"uptime"`
+	SignalStrength int                        `json:"signal,omitempty"`
+	Extra          map[string]json.RawMessage `json:"-"`
 }
 
 // NetworkSite represents a site in Unifi Network
 type NetworkSite struct {
-	ID         string `json:"_id"`
-	Name       string `json:"name"`
-	ExternalID string `json:"external_id"`
-	Desc       string `json:"desc"`
-	Role       string `json:"role"`
-	Status     string `json:"status"`
-	NumSta     int    `json:"num_sta"`
-	RxPackets  int64  `json:"rx_packets"`
-	TxPackets  int64  `json:"tx_packets"`
+	ID         string                     `json:"_id"`
+	Name       string                     `json:"name"`
+	ExternalID string                     `json:"external_id"`
+	Desc       string                     `json:"desc"`
+	Role       string                     `json:"role"`
+	Status     string                     `json:"status"`
+	NumSta     int                        `json:"num_sta"`
+	RxPackets  int64                      `json:"rx_packets"`
+	TxPackets  int64                      `json:"tx_packets"`
+	Extra      map[string]json.RawMessage `json:"-"`
 }
 
 // NetworkWiFiNetwork represents a WiFi network
 type NetworkWiFiNetwork struct {
-	ID           string `json:"_id"`
-	Name         string `json:"name"`
-	SSID         string `json:"ssid"`
-	Security     string `json:"security"`
-	Enabled      bool   `json:"enabled"`
-	ChannelWidth string `json:"channel_width,omitempty"`
-	Channel      int    `json:"channel,omitempty"`
-	Band         string `json:"band"`
+	ID           string                     `json:"_id"`
+	Name         string                     `json:"name"`
+	SSID         string                     `json:"ssid"`
+	Security     string                     `json:"security"`
+	Enabled      bool                       `json:"enabled"`
+	ChannelWidth string                     `json:"channel_width,omitempty"`
+	Channel      int                        `json:"channel,omitempty"`
+	Band         string                     `json:"band"`
+	Extra        map[string]json.RawMessage `json:"-"`
 }
 
 // NetworkStatsData represents network statistics
@@ -71,80 +117,94 @@ type NetworkStatsData struct {
 
 // NetworkClientDevice represents a connected network client
 type NetworkClientDevice struct {
-	MAC       string `json:"mac"`
-	Name      string `json:"name"`
-	IP        string `json:"ip"`
-	Hostname  string `json:"hostname"`
-	Signal    int    `json:"signal,omitempty"`
-	RSSI      int    `json:"rssi,omitempty"`
-	TxBytes   int64  `json:"tx_bytes,omitempty"`
-	RxBytes   int64  `json:"rx_bytes,omitempty"`
-	TxRate    int64  `json:"tx_rate,omitempty"`
-	RxRate    int64  `json:"rx_rate,omitempty"`
-	FirstSeen int64  `json:"first_seen,omitempty"`
-	LastSeen  int64  `json:"last_seen,omitempty"`
+	MAC       string                     `json:"mac"`
+	Name      string                     `json:"name"`
+	IP        string                     `json:"ip"`
+	Hostname  string                     `json:"hostname"`
+	Signal    int                        `json:"signal,omitempty"`
+	RSSI      int                        `json:"rssi,omitempty"`
+	TxBytes   int64                      `json:"tx_bytes,omitempty"`
+	RxBytes   int64                      `json:"rx_bytes,omitempty"`
+	TxRate    int64                      `json:"tx_rate,omitempty"`
+	RxRate    int64                      `json:"rx_rate,omitempty"`
+	FirstSeen int64                      `json:"first_seen,omitempty"`
+	LastSeen  int64                      `json:"last_seen,omitempty"`
+	Extra     map[string]json.RawMessage `json:"-"`
 }
 
 // NetworkVPNServer represents a VPN server configuration
 type NetworkVPNServer struct {
-	ID   string `json:"_id"`
-	Name string `json:"name"`
-	Desc string `json:"desc"`
+	ID    string                     `json:"_id"`
+	Name  string                     `json:"name"`
+	Desc  string                     `json:"desc"`
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // NewNetworkClient creates a new Unifi Network API client
-func NewNetworkClient(baseURL, apiKey string, skipSSLVerify bool) *NetworkClient {
-	var tlsConfig *tls.Config
-	if skipSSLVerify {
-		// Disable SSL verification for self-signed certificates
-		tlsConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
+func NewNetworkClient(baseURL, apiKey string, skipSSLVerify bool, opts ...ClientOption) *NetworkClient {
+	nc := &NetworkClient{
+		baseURL:   baseURL,
+		apiKey:    apiKey,
+		retry:     DefaultRetryPolicy(),
+		transport: DefaultTransportConfig(),
+		logger:    logrus.WithField("component", "NetworkClient"),
+
+		controllerType: ControllerAuto,
+		authTTL:        DefaultAuthTTL,
 	}
 
-	httpClient := &http.Client{
-		Timeout: 30 * time.Second,
+	for _, opt := range opts {
+		opt(nc)
 	}
-	if tlsConfig != nil {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: tlsConfig,
+
+	tlsConfig := nc.tlsConfig
+	if skipSSLVerify {
+		// Disable SSL verification for self-signed certificates
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		} else {
+			tlsConfig = tlsConfig.Clone()
 		}
+		tlsConfig.InsecureSkipVerify = true
+	}
+	nc.httpClient = &http.Client{
+		Timeout:   nc.transport.Timeout,
+		Transport: newTransport(nc.transport, tlsConfig),
 	}
 
-	return &NetworkClient{
-		baseURL:    baseURL,
-		apiKey:     apiKey,
-		httpClient: httpClient,
-		logger:     logrus.WithField("component", "NetworkClient"),
+	nc.root = networkRoot(nc.baseURL, nc.controllerType)
+	if nc.sessionAuth() {
+		nc.enableSession()
 	}
-}
 
-// Authenticate verifies API key connectivity
-func (nc *NetworkClient) Authenticate(ctx context.Context) error {
-	nc.logger.Debug("Verifying Unifi Network API key")
+	return nc
+}
 
-	if nc.apiKey == "" {
-		return fmt.Errorf("API key not configured")
+// WithAuditRecorder reports every write request to recorder
+func WithAuditRecorder(recorder AuditRecorder) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.auditor = recorder
 	}
+}
 
-	nc.logger.Info("Unifi Network API key verified")
-	return nil
+// ReadOnly reports whether the client refuses write requests
+func (nc *NetworkClient) ReadOnly() bool {
+	return nc.readOnly
 }
 
 // GetSites retrieves all sites from Unifi Network
 func (nc *NetworkClient) GetSites(ctx context.Context) ([]NetworkSite, error) {
 	nc.logger.Debug("Fetching sites from Unifi Network")
 
-	url := fmt.Sprintf("%s/proxy/network/api/self/sites", nc.baseURL)
+	url := nc.networkURL("/api/self/sites")
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -152,7 +212,7 @@ func (nc *NetworkClient) GetSites(ctx context.Context) ([]NetworkSite, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(req, resp.StatusCode, resp.Header, bodyBytes)
 	}
 
 	var response struct {
@@ -170,16 +230,15 @@ func (nc *NetworkClient) GetSites(ctx context.Context) ([]NetworkSite, error) {
 func (nc *NetworkClient) GetDevices(ctx context.Context, siteID string) ([]NetworkDevice, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching devices from Unifi Network")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/devices", nc.baseURL, siteID)
+	url := nc.networkURL("/integration/v1/sites/%s/devices", siteID)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -187,7 +246,7 @@ func (nc *NetworkClient) GetDevices(ctx context.Context, siteID string) ([]Netwo
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(req, resp.StatusCode, resp.Header, bodyBytes)
 	}
 
 	var response struct {
@@ -205,16 +264,15 @@ func (nc *NetworkClient) GetDevices(ctx context.Context, siteID string) ([]Netwo
 func (nc *NetworkClient) GetWiFiNetworks(ctx context.Context, siteID string) ([]NetworkWiFiNetwork, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching WiFi networks from Unifi Network")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/networks", nc.baseURL, siteID)
+	url := nc.networkURL("/integration/v1/sites/%s/networks", siteID)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -222,7 +280,7 @@ func (nc *NetworkClient) GetWiFiNetworks(ctx context.Context, siteID string) ([]
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(req, resp.StatusCode, resp.Header, bodyBytes)
 	}
 
 	var response struct {
@@ -236,150 +294,75 @@ func (nc *NetworkClient) GetWiFiNetworks(ctx context.Context, siteID string) ([]
 	return response.Data, nil
 }
 
-// GetClientStats retrieves client statistics for a site
-func (nc *NetworkClient) GetClientStats(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+// GetClientStats retrieves client statistics
+func (nc *NetworkClient) GetClientStats(ctx context.Context, siteID string) ([]NetworkClientDevice, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching client stats from Unifi Network")
 
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/stat/sta", nc.baseURL, siteID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	url := nc.networkURL("/api/s/%s/stat/sta", siteID)
+	stats, err := getList[NetworkClientDevice](ctx, nc, url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	nc.logger.WithField("count", len(response.Data)).Debug("Retrieved client stats")
-	return response.Data, nil
+	nc.logger.WithField("count", len(stats)).Debug("Retrieved client stats")
+	return stats, nil
 }
 
 // GetClients retrieves connected clients with pagination support
-func (nc *NetworkClient) GetClients(ctx context.Context, siteID string, limit, offset int) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetClients(ctx context.Context, siteID string, limit, offset int) ([]NetworkConnectedClient, error) {
 	nc.logger.WithFields(map[string]interface{}{
 		"site_id": siteID,
 		"limit":   limit,
 		"offset":  offset,
 	}).Debug("Fetching clients from Unifi Network")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/clients", nc.baseURL, siteID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	url := nc.networkURL("/integration/v1/sites/%s/clients", siteID)
+	clients, err := getList[NetworkConnectedClient](ctx, nc, url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
-	nc.logger.WithField("count", len(response.Data)).Debug("Retrieved clients")
-	return response.Data, nil
+	nc.logger.WithField("count", len(clients)).Debug("Retrieved clients")
+	return clients, nil
 }
-func (nc *NetworkClient) GetHealth(ctx context.Context, siteID string) (map[string]interface{}, error) {
-	nc.logger.WithField("site_id", siteID).Debug("Fetching health status from Unifi Network")
-
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/health", nc.baseURL, siteID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
+// GetHealth retrieves the health status of a site
+func (nc *NetworkClient) GetHealth(ctx context.Context, siteID string) (*NetworkHealthSubsystem, error) {
+	nc.logger.WithField("site_id", siteID).Debug("Fetching health status from Unifi Network")
 
-	resp, err := nc.httpClient.Do(req)
+	url := nc.networkURL("/integration/v1/sites/%s/health", siteID)
+	subsystems, err := getList[NetworkHealthSubsystem](ctx, nc, url)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if len(subsystems) > 0 {
+		return &subsystems[0], nil
 	}
 
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if len(response.Data) > 0 {
-		return response.Data[0], nil
-	}
-
-	return make(map[string]interface{}), nil
+	return &NetworkHealthSubsystem{}, nil
 }
 
 // GetInfo retrieves UniFi Network application information
-func (nc *NetworkClient) GetInfo(ctx context.Context) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetInfo(ctx context.Context) (*NetworkApplicationInfo, error) {
 	nc.logger.Debug("Fetching UniFi Network info")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/info", nc.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	url := nc.networkURL("/integration/v1/info")
+	body, err := nc.getBody(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var info map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+	var info NetworkApplicationInfo
+	if err := json.Unmarshal(body, &info); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return info, nil
+	return &info, nil
 }
 
 // GetDeviceStats gets latest statistics for a device
-func (nc *NetworkClient) GetDeviceStats(ctx context.Context, siteID, deviceID string) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetDeviceStats(ctx context.Context, siteID, deviceID string) (*NetworkDevice, error) {
 	nc.logger.WithFields(map[string]interface{}{
 		"site_id":   siteID,
 		"device_id": deviceID,
@@ -391,300 +374,126 @@ func (nc *NetworkClient) GetDeviceStats(ctx context.Context, siteID, deviceID st
 		return nil, fmt.Errorf("failed to get devices: %w", err)
 	}
 
-	for _, device := range devices {
-		if device.ID == deviceID {
-			// Convert device struct to map
-			devMap := make(map[string]interface{})
-			data, _ := json.Marshal(device)
-			json.Unmarshal(data, &devMap)
-			return devMap, nil
+	for i := range devices {
+		if devices[i].ID == deviceID {
+			return &devices[i], nil
 		}
 	}
 
-	return nil, fmt.Errorf("device not found: %s", deviceID)
+	return nil, notFoundError(http.MethodGet, pathOf(nc.networkURL("/integration/v1/sites/%s/devices/%s", siteID, deviceID)), "device")
 }
 
 // GetWiFiBroadcasts retrieves WiFi broadcasts (SSIDs)
-func (nc *NetworkClient) GetWiFiBroadcasts(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetWiFiBroadcasts(ctx context.Context, siteID string) ([]NetworkWiFiBroadcast, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching WiFi broadcasts")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/wifi/broadcasts", nc.baseURL, siteID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return response.Data, nil
+	url := nc.networkURL("/integration/v1/sites/%s/wifi/broadcasts", siteID)
+	return getList[NetworkWiFiBroadcast](ctx, nc, url)
 }
 
 // GetFirewallZones retrieves firewall zones
-func (nc *NetworkClient) GetFirewallZones(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetFirewallZones(ctx context.Context, siteID string) ([]NetworkFirewallZone, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching firewall zones")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/firewall/zones", nc.baseURL, siteID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return response.Data, nil
+	url := nc.networkURL("/integration/v1/sites/%s/firewall/zones", siteID)
+	return getList[NetworkFirewallZone](ctx, nc, url)
 }
 
 // GetACLRules retrieves ACL rules
-func (nc *NetworkClient) GetACLRules(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetACLRules(ctx context.Context, siteID string) ([]NetworkACLRule, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching ACL rules")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/acl-rules", nc.baseURL, siteID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return response.Data, nil
+	url := nc.networkURL("/integration/v1/sites/%s/acl-rules", siteID)
+	return getList[NetworkACLRule](ctx, nc, url)
 }
 
 // GetHotspotVouchers retrieves hotspot vouchers
-func (nc *NetworkClient) GetHotspotVouchers(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetHotspotVouchers(ctx context.Context, siteID string) ([]NetworkHotspotVoucher, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching hotspot vouchers")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/hotspot/vouchers", nc.baseURL, siteID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return response.Data, nil
+	url := nc.networkURL("/integration/v1/sites/%s/hotspot/vouchers", siteID)
+	return getList[NetworkHotspotVoucher](ctx, nc, url)
 }
 
 // GetPendingDevices retrieves devices pending adoption
-func (nc *NetworkClient) GetPendingDevices(ctx context.Context) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetPendingDevices(ctx context.Context) ([]NetworkPendingDevice, error) {
 	nc.logger.Debug("Fetching pending devices")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/pending-devices", nc.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return response.Data, nil
+	url := nc.networkURL("/integration/v1/pending-devices")
+	return getList[NetworkPendingDevice](ctx, nc, url)
 }
 
 // GetDPICategories retrieves DPI categories
-func (nc *NetworkClient) GetDPICategories(ctx context.Context) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetDPICategories(ctx context.Context) ([]NetworkDPICategory, error) {
 	nc.logger.Debug("Fetching DPI categories")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/dpi/categories", nc.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data []map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return response.Data, nil
+	url := nc.networkURL("/integration/v1/dpi/categories")
+	return getList[NetworkDPICategory](ctx, nc, url)
 }
 
 // GetClientDetailed retrieves detailed information about a specific client
-func (nc *NetworkClient) GetClientDetailed(ctx context.Context, siteID, clientMAC string) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetClientDetailed(ctx context.Context, siteID, clientMAC string) (*NetworkConnectedClient, error) {
 	nc.logger.WithFields(logrus.Fields{
 		"site_id": siteID,
 		"mac":     clientMAC,
 	}).Debug("Fetching detailed client info")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/clients/%s", nc.baseURL, siteID, clientMAC)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := nc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var response struct {
-		Data map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return response.Data, nil
+	url := nc.networkURL("/integration/v1/sites/%s/clients/%s", siteID, clientMAC)
+	return getObject[NetworkConnectedClient](ctx, nc, url)
 }
 
-// GetDeviceDetailed retrieves detailed information about a specific device
-func (nc *NetworkClient) GetDeviceDetailed(ctx context.Context, siteID, deviceID string) (map[string]interface{}, error) {
+// GetDeviceDetailed retrieves the full record of a device, including its port
+// and radio tables, uplink, system stats, temperatures and management network
+// configuration. deviceID is the device's ID or MAC address. The site is needed
+// whole because the ID lookup uses its external ID and the record its name.
+func (nc *NetworkClient) GetDeviceDetailed(ctx context.Context, site NetworkSite, deviceID string) (*NetworkDeviceDetail, error) {
 	nc.logger.WithFields(logrus.Fields{
-		"site_id":   siteID,
+		"site":      site.Name,
 		"device_id": deviceID,
 	}).Debug("Fetching detailed device info")
 
-	// Get all devices and filter for the specific one
-	devices, err := nc.GetDevices(ctx, siteID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get devices: %w", err)
-	}
-
-	for _, device := range devices {
-		if device.ID == deviceID {
-			// Convert device struct to map
-			devMap := make(map[string]interface{})
-			data, _ := json.Marshal(device)
-			json.Unmarshal(data, &devMap)
-			return devMap, nil
+	// The legacy API addresses devices by MAC address, so an ID is first looked
+	// up in the integration API, which also reports the device's features
+	mac := deviceID
+	var features []string
+	if _, err := net.ParseMAC(deviceID); err != nil {
+		if !nc.IntegrationAPI() {
+			return nil, fmt.Errorf("pass device %s by its MAC address, as looking up its ID needs an API key: %w", deviceID, ErrAPIKeyRequired)
+		}
+		device, err := nc.getIntegrationDevice(ctx, site.ExternalID, deviceID)
+		if err != nil {
+			return nil, err
+		}
+		mac, features = device.macAddress(), device.features()
+		if mac == "" {
+			return nil, fmt.Errorf("device %s has no MAC address", deviceID)
 		}
 	}
 
-	return nil, fmt.Errorf("device not found: %s", deviceID)
+	url := nc.networkURL("/api/s/%s/stat/device/%s", site.Name, strings.ToLower(mac))
+	detail, err := getObject[NetworkDeviceDetail](ctx, nc, url)
+	if err != nil {
+		return nil, err
+	}
+	if len(detail.Features) == 0 {
+		detail.Features = features
+	}
+	return detail, nil
 }
 
 // GetVPNServers retrieves VPN server configurations
 func (nc *NetworkClient) GetVPNServers(ctx context.Context, siteID string) ([]NetworkVPNServer, error) {
 	nc.logger.WithField("site_id", siteID).Debug("Fetching VPN servers")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/sites/%s/vpn/servers", nc.baseURL, siteID)
+	url := nc.networkURL("/integration/v1/sites/%s/vpn/servers", siteID)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -692,7 +501,7 @@ func (nc *NetworkClient) GetVPNServers(ctx context.Context, siteID string) ([]Ne
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(req, resp.StatusCode, resp.Header, bodyBytes)
 	}
 
 	var response struct {
@@ -710,16 +519,15 @@ func (nc *NetworkClient) GetVPNServers(ctx context.Context, siteID string) ([]Ne
 func (nc *NetworkClient) CheckEndpointHealth(ctx context.Context) (map[string]interface{}, error) {
 	nc.logger.Debug("Performing health check on Unifi Network endpoint")
 
-	url := fmt.Sprintf("%s/proxy/network/integration/v1/info", nc.baseURL)
+	url := nc.networkURL("/integration/v1/info")
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-API-KEY", nc.apiKey)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -756,285 +564,471 @@ func (nc *NetworkClient) CheckEndpointHealth(ctx context.Context) (map[string]in
 }
 
 // GetWiFiNetworkDetailed retrieves details for a specific WiFi network
-func (nc *NetworkClient) GetWiFiNetworkDetailed(ctx context.Context, siteID, networkID string) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetWiFiNetworkDetailed(ctx context.Context, siteID, networkID string) (*NetworkConfig, error) {
 	nc.logger.Debugf("Fetching WiFi network details for ID: %s", networkID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/networkconf/%s", nc.baseURL, siteID, networkID)
-	return nc.makeSingleRequest(ctx, url)
+	url := nc.restURL(siteID, ResourceWiFiNetwork, networkID)
+	return getObject[NetworkConfig](ctx, nc, url)
 }
 
 // GetFirewallZoneDetailed retrieves details for a specific firewall zone
-func (nc *NetworkClient) GetFirewallZoneDetailed(ctx context.Context, siteID, zoneID string) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetFirewallZoneDetailed(ctx context.Context, siteID, zoneID string) (*NetworkFirewallZone, error) {
 	nc.logger.Debugf("Fetching firewall zone details for ID: %s", zoneID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/firewallzone/%s", nc.baseURL, siteID, zoneID)
-	return nc.makeSingleRequest(ctx, url)
+	url := nc.restURL(siteID, ResourceFirewallZone, zoneID)
+	return getObject[NetworkFirewallZone](ctx, nc, url)
 }
 
 // GetACLRuleDetailed retrieves details for a specific ACL rule
-func (nc *NetworkClient) GetACLRuleDetailed(ctx context.Context, siteID, ruleID string) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetACLRuleDetailed(ctx context.Context, siteID, ruleID string) (*NetworkACLRule, error) {
 	nc.logger.Debugf("Fetching ACL rule details for ID: %s", ruleID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/rule/%s", nc.baseURL, siteID, ruleID)
-	return nc.makeSingleRequest(ctx, url)
+	url := nc.restURL(siteID, ResourceACLRule, ruleID)
+	return getObject[NetworkACLRule](ctx, nc, url)
 }
 
 // GetHotspotVoucherDetailed retrieves details for a specific hotspot voucher
-func (nc *NetworkClient) GetHotspotVoucherDetailed(ctx context.Context, siteID, voucherID string) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetHotspotVoucherDetailed(ctx context.Context, siteID, voucherID string) (*NetworkHotspotVoucher, error) {
 	nc.logger.Debugf("Fetching hotspot voucher details for ID: %s", voucherID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/hotspotop/%s", nc.baseURL, siteID, voucherID)
-	return nc.makeSingleRequest(ctx, url)
+	url := nc.restURL(siteID, ResourceHotspotVoucher, voucherID)
+	return getObject[NetworkHotspotVoucher](ctx, nc, url)
+}
+
+// GetVPNTunnelDetailed retrieves details for a specific VPN site-to-site tunnel
+func (nc *NetworkClient) GetVPNTunnelDetailed(ctx context.Context, siteID, tunnelID string) (*NetworkVPNTunnel, error) {
+	nc.logger.Debugf("Fetching VPN tunnel details for ID: %s", tunnelID)
+	url := nc.restURL(siteID, ResourceVPNTunnel, tunnelID)
+	return getObject[NetworkVPNTunnel](ctx, nc, url)
 }
 
 // GetVPNTunnels retrieves VPN site-to-site tunnel configurations
-func (nc *NetworkClient) GetVPNTunnels(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetVPNTunnels(ctx context.Context, siteID string) ([]NetworkVPNTunnel, error) {
 	nc.logger.Debug("Fetching VPN site-to-site tunnels")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/vpnserverconfig", nc.baseURL, siteID)
-	return nc.makeArrayRequest(ctx, url)
+	url := nc.restURL(siteID, ResourceVPNTunnel, "")
+	return getList[NetworkVPNTunnel](ctx, nc, url)
 }
 
 // GetDeviceTags retrieves device tags from a site
-func (nc *NetworkClient) GetDeviceTags(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetDeviceTags(ctx context.Context, siteID string) ([]NetworkDeviceTag, error) {
 	nc.logger.Debug("Fetching device tags")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/tag", nc.baseURL, siteID)
-	return nc.makeArrayRequest(ctx, url)
+	url := nc.networkURL("/api/s/%s/rest/tag", siteID)
+	return getList[NetworkDeviceTag](ctx, nc, url)
 }
 
 // GetWANConfig retrieves WAN configuration from a site
-func (nc *NetworkClient) GetWANConfig(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetWANConfig(ctx context.Context, siteID string) ([]NetworkWANConfig, error) {
 	nc.logger.Debug("Fetching WAN configuration")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/wanconf", nc.baseURL, siteID)
-	return nc.makeArrayRequest(ctx, url)
+	url := nc.networkURL("/api/s/%s/rest/wanconf", siteID)
+	return getList[NetworkWANConfig](ctx, nc, url)
 }
 
 // GetTrafficRules retrieves traffic matching rules from a site
-func (nc *NetworkClient) GetTrafficRules(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetTrafficRules(ctx context.Context, siteID string) ([]NetworkTrafficRule, error) {
 	nc.logger.Debug("Fetching traffic matching rules")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/trafficrule", nc.baseURL, siteID)
-	return nc.makeArrayRequest(ctx, url)
+	url := nc.restURL(siteID, ResourceTrafficRule, "")
+	return getList[NetworkTrafficRule](ctx, nc, url)
 }
 
 // GetTrafficRuleDetailed retrieves details for a specific traffic matching rule
-func (nc *NetworkClient) GetTrafficRuleDetailed(ctx context.Context, siteID, ruleID string) (map[string]interface{}, error) {
+func (nc *NetworkClient) GetTrafficRuleDetailed(ctx context.Context, siteID, ruleID string) (*NetworkTrafficRule, error) {
 	nc.logger.Debugf("Fetching traffic rule details for ID: %s", ruleID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/trafficrule/%s", nc.baseURL, siteID, ruleID)
-	return nc.makeSingleRequest(ctx, url)
+	url := nc.restURL(siteID, ResourceTrafficRule, ruleID)
+	return getObject[NetworkTrafficRule](ctx, nc, url)
 }
 
 // GetRADIUSProfiles retrieves RADIUS server profiles from a site
-func (nc *NetworkClient) GetRADIUSProfiles(ctx context.Context, siteID string) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetRADIUSProfiles(ctx context.Context, siteID string) ([]NetworkRADIUSProfile, error) {
 	nc.logger.Debug("Fetching RADIUS profiles")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/radiusprofile", nc.baseURL, siteID)
-	return nc.makeArrayRequest(ctx, url)
+	url := nc.networkURL("/api/s/%s/rest/radiusprofile", siteID)
+	return getList[NetworkRADIUSProfile](ctx, nc, url)
 }
 
 // GetDPIApplications retrieves DPI applications list
-func (nc *NetworkClient) GetDPIApplications(ctx context.Context) ([]map[string]interface{}, error) {
+func (nc *NetworkClient) GetDPIApplications(ctx context.Context) ([]NetworkDPIApplication, error) {
 	nc.logger.Debug("Fetching DPI applications")
-	url := fmt.Sprintf("%s/proxy/network/api/v1/dpi/applications", nc.baseURL)
-	return nc.makeArrayRequest(ctx, url)
+	url := nc.networkURL("/api/v1/dpi/applications")
+	return getList[NetworkDPIApplication](ctx, nc, url)
 }
 
-// makeSingleRequest is a helper to fetch a single resource
-func (nc *NetworkClient) makeSingleRequest(ctx context.Context, url string) (map[string]interface{}, error) {
+// getBody sends a GET request and returns the response body
+func (nc *NetworkClient) getBody(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", nc.apiKey)
+	req.Header.Set("Accept", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(req, resp.StatusCode, resp.Header, body)
+	}
+	return body, nil
+}
+
+// getData sends a GET request and returns the data member of the response
+func (nc *NetworkClient) getData(ctx context.Context, url string) (json.RawMessage, error) {
+	body, err := nc.getBody(ctx, url)
+	if err != nil {
+		return nil, err
 	}
 
 	var response struct {
-		Data map[string]interface{} `json:"data"`
+		Data json.RawMessage `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
 	return response.Data, nil
 }
 
-// makeArrayRequest is a helper to fetch array resources
-func (nc *NetworkClient) makeArrayRequest(ctx context.Context, url string) ([]map[string]interface{}, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// queryData posts a command that only reads state, such as listing available
+// firmware, and returns the data member of the response. Unlike writes it is
+// neither refused in read-only mode nor audited.
+func (nc *NetworkClient) queryData(ctx context.Context, url string, payload map[string]interface{}) (json.RawMessage, error) {
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("X-API-KEY", nc.apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(req, resp.StatusCode, resp.Header, body)
+	}
+	if apiErr := legacyError(req, resp.StatusCode, resp.Header, body); apiErr != nil {
+		return nil, apiErr
 	}
 
 	var response struct {
-		Data []map[string]interface{} `json:"data"`
+		Data json.RawMessage `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
 	return response.Data, nil
 }
 
-// makePatchRequest is a helper to send PATCH requests
-func (nc *NetworkClient) makePatchRequest(ctx context.Context, url string, payload map[string]interface{}) (map[string]interface{}, error) {
-	bodyBytes, err := json.Marshal(payload)
+// getList fetches a collection and decodes its items into T
+func getList[T any](ctx context.Context, nc *NetworkClient, url string) ([]T, error) {
+	data, err := nc.getData(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, err
 	}
+	return decodeList[T](data)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "PATCH", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// decodeList decodes the data member of a collection response into T
+func decodeList[T any](data json.RawMessage) ([]T, error) {
+	items := []T{}
+	if len(data) > 0 && string(data) != "null" {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
 	}
-	req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	return items, nil
+}
 
-	resp, err := nc.httpClient.Do(req)
+// getObject fetches a single object and decodes it into T. Legacy endpoints
+// return the object wrapped in a one-element array.
+func getObject[T any](ctx context.Context, nc *NetworkClient, url string) (*T, error) {
+	data, err := nc.getData(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(items) == 0 {
+			return nil, notFoundError(http.MethodGet, pathOf(url), "object")
+		}
+		data = items[0]
 	}
 
-	var response struct {
-		Data map[string]interface{} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	var object T
+	if err := json.Unmarshal(data, &object); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	return &object, nil
+}
 
-	return response.Data, nil
+// makeSingleRequest is a helper to fetch a single resource as a generic JSON object
+func (nc *NetworkClient) makeSingleRequest(ctx context.Context, url string) (map[string]interface{}, error) {
+	object, err := getObject[map[string]interface{}](ctx, nc, url)
+	if err != nil {
+		return nil, err
+	}
+	return *object, nil
+}
+
+// makePatchRequest is a helper to send PATCH requests
+func (nc *NetworkClient) makePatchRequest(ctx context.Context, url string, payload map[string]interface{}) (map[string]interface{}, error) {
+	return nc.makeWriteRequest(ctx, "PATCH", url, payload)
 }
 
 // makePostRequest is a helper to send POST requests
 func (nc *NetworkClient) makePostRequest(ctx context.Context, url string, payload map[string]interface{}) (map[string]interface{}, error) {
-	bodyBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	return nc.makeWriteRequest(ctx, "POST", url, payload)
+}
+
+// makeDeleteRequest is a helper to send DELETE requests
+func (nc *NetworkClient) makeDeleteRequest(ctx context.Context, url string) error {
+	_, err := nc.makeWriteRequest(ctx, "DELETE", url, nil)
+	return err
+}
+
+// makeWriteRequest enforces read-only mode and reports every write attempt to the audit recorder
+func (nc *NetworkClient) makeWriteRequest(ctx context.Context, method, url string, payload map[string]interface{}) (map[string]interface{}, error) {
+	record := newWriteRecord(method, url, payload)
+
+	if nc.readOnly {
+		nc.logger.WithField("url", url).Warnf("Refusing %s request on read-only client", method)
+		record.Err = ErrReadOnly
+		nc.recordWrite(ctx, record)
+		return nil, ErrReadOnly
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	// Snapshot the object before changing it, best effort
+	if nc.auditor != nil && method != "POST" {
+		if before, err := nc.makeSingleRequest(WithoutCache(ctx), url); err == nil {
+			record.Before = before
+		} else {
+			nc.logger.WithError(err).Debug("Failed to snapshot object before write")
+		}
+	}
+
+	start := time.Now()
+	data, statusCode, err := nc.sendWriteRequest(ctx, method, url, payload)
+	record.Duration = time.Since(start)
+	record.StatusCode = statusCode
+	record.After = data
+	record.Err = err
+	if record.ObjectID == "" && data != nil {
+		record.ObjectID, _ = data["_id"].(string)
+	}
+	nc.recordWrite(ctx, record)
+
+	return data, err
+}
+
+// sendWriteRequest sends a JSON write request and decodes the returned object.
+// A nil payload sends no body, as used by DELETE.
+func (nc *NetworkClient) sendWriteRequest(ctx context.Context, method, url string, payload map[string]interface{}) (map[string]interface{}, int, error) {
+	var body io.Reader
+	if payload != nil {
+		bodyBytes, err := json.Marshal(payload)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-	req.Header.Set("X-API-KEY", nc.apiKey)
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := nc.httpClient.Do(req)
+	resp, err := nc.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, 0, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return nil, resp.StatusCode, newAPIError(req, resp.StatusCode, resp.Header, respBody)
+	}
+	// Legacy endpoints may report a rejected write in the envelope of a 200 response
+	if apiErr := legacyError(req, resp.StatusCode, resp.Header, respBody); apiErr != nil {
+		return nil, apiErr.StatusCode, apiErr
 	}
 
 	var response struct {
-		Data map[string]interface{} `json:"data"`
+		Data json.RawMessage `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if len(bytes.TrimSpace(respBody)) > 0 {
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
 	}
 
-	return response.Data, nil
+	data, err := decodeWriteData(response.Data)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return data, resp.StatusCode, nil
+}
+
+// decodeWriteData returns the object a write responded with. Legacy endpoints
+// wrap it in a one-element array and DELETE answers with an empty one.
+func decodeWriteData(raw json.RawMessage) (map[string]interface{}, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return map[string]interface{}{}, nil
+	}
+
+	if raw[0] == '[' {
+		var items []map[string]interface{}
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			return map[string]interface{}{}, nil
+		}
+		return items[0], nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
 }
 
 // PatchWiFiNetwork updates WiFi network settings
 func (nc *NetworkClient) PatchWiFiNetwork(ctx context.Context, siteID, networkID string, settings map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debugf("Updating WiFi network settings for ID: %s", networkID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/networkconf/%s", nc.baseURL, siteID, networkID)
+	url := nc.restURL(siteID, ResourceWiFiNetwork, networkID)
 	return nc.makePatchRequest(ctx, url, settings)
 }
 
 // PatchFirewallZone updates firewall zone settings
 func (nc *NetworkClient) PatchFirewallZone(ctx context.Context, siteID, zoneID string, settings map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debugf("Updating firewall zone settings for ID: %s", zoneID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/firewallzone/%s", nc.baseURL, siteID, zoneID)
+	url := nc.restURL(siteID, ResourceFirewallZone, zoneID)
 	return nc.makePatchRequest(ctx, url, settings)
 }
 
 // PatchACLRule updates an ACL rule
 func (nc *NetworkClient) PatchACLRule(ctx context.Context, siteID, ruleID string, settings map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debugf("Updating ACL rule settings for ID: %s", ruleID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/rule/%s", nc.baseURL, siteID, ruleID)
+	url := nc.restURL(siteID, ResourceACLRule, ruleID)
 	return nc.makePatchRequest(ctx, url, settings)
 }
 
 // PatchHotspotVoucher updates a hotspot voucher
 func (nc *NetworkClient) PatchHotspotVoucher(ctx context.Context, siteID, voucherID string, settings map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debugf("Updating hotspot voucher settings for ID: %s", voucherID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/hotspotop/%s", nc.baseURL, siteID, voucherID)
+	url := nc.restURL(siteID, ResourceHotspotVoucher, voucherID)
 	return nc.makePatchRequest(ctx, url, settings)
 }
 
 // PatchTrafficRule updates a traffic matching rule
 func (nc *NetworkClient) PatchTrafficRule(ctx context.Context, siteID, ruleID string, settings map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debugf("Updating traffic rule settings for ID: %s", ruleID)
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/trafficrule/%s", nc.baseURL, siteID, ruleID)
+	url := nc.restURL(siteID, ResourceTrafficRule, ruleID)
 	return nc.makePatchRequest(ctx, url, settings)
 }
 
 // CreateWiFiNetwork creates a new WiFi network
 func (nc *NetworkClient) CreateWiFiNetwork(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debug("Creating new WiFi network")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/networkconf", nc.baseURL, siteID)
+	url := nc.restURL(siteID, ResourceWiFiNetwork, "")
 	return nc.makePostRequest(ctx, url, config)
 }
 
 // CreateFirewallZone creates a new firewall zone
 func (nc *NetworkClient) CreateFirewallZone(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debug("Creating new firewall zone")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/firewallzone", nc.baseURL, siteID)
+	url := nc.restURL(siteID, ResourceFirewallZone, "")
 	return nc.makePostRequest(ctx, url, config)
 }
 
 // CreateACLRule creates a new ACL rule
 func (nc *NetworkClient) CreateACLRule(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debug("Creating new ACL rule")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/rule", nc.baseURL, siteID)
+	url := nc.restURL(siteID, ResourceACLRule, "")
 	return nc.makePostRequest(ctx, url, config)
 }
 
 // CreateHotspotVoucher creates a new hotspot voucher
 func (nc *NetworkClient) CreateHotspotVoucher(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debug("Creating new hotspot voucher")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/hotspotop", nc.baseURL, siteID)
+	url := nc.restURL(siteID, ResourceHotspotVoucher, "")
 	return nc.makePostRequest(ctx, url, config)
 }
 
 // CreateTrafficRule creates a new traffic matching rule
 func (nc *NetworkClient) CreateTrafficRule(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debug("Creating new traffic matching rule")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/trafficrule", nc.baseURL, siteID)
+	url := nc.restURL(siteID, ResourceTrafficRule, "")
 	return nc.makePostRequest(ctx, url, config)
 }
 
 // CreateVPNTunnel creates a new VPN site-to-site tunnel
 func (nc *NetworkClient) CreateVPNTunnel(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
 	nc.logger.Debug("Creating new VPN site-to-site tunnel")
-	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/vpnserverconfig", nc.baseURL, siteID)
+	url := nc.restURL(siteID, ResourceVPNTunnel, "")
 	return nc.makePostRequest(ctx, url, config)
 }
+
+// DeleteWiFiNetwork deletes a WiFi network
+func (nc *NetworkClient) DeleteWiFiNetwork(ctx context.Context, siteID, networkID string) error {
+	nc.logger.Debugf("Deleting WiFi network with ID: %s", networkID)
+	url := nc.restURL(siteID, ResourceWiFiNetwork, networkID)
+	return nc.makeDeleteRequest(ctx, url)
+}
+
+// DeleteFirewallZone deletes a firewall zone
+func (nc *NetworkClient) DeleteFirewallZone(ctx context.Context, siteID, zoneID string) error {
+	nc.logger.Debugf("Deleting firewall zone with ID: %s", zoneID)
+	url := nc.restURL(siteID, ResourceFirewallZone, zoneID)
+	return nc.makeDeleteRequest(ctx, url)
+}
+
+// DeleteACLRule deletes a ACL rule
+func (nc *NetworkClient) DeleteACLRule(ctx context.Context, siteID, ruleID string) error {
+	nc.logger.Debugf("Deleting ACL rule with ID: %s", ruleID)
+	url := nc.restURL(siteID, ResourceACLRule, ruleID)
+	return nc.makeDeleteRequest(ctx, url)
+}
+
+// DeleteHotspotVoucher deletes a hotspot voucher
+func (nc *NetworkClient) DeleteHotspotVoucher(ctx context.Context, siteID, voucherID string) error {
+	nc.logger.Debugf("Deleting hotspot voucher with ID: %s", voucherID)
+	url := nc.restURL(siteID, ResourceHotspotVoucher, voucherID)
+	return nc.makeDeleteRequest(ctx, url)
+}
+
+// DeleteTrafficRule deletes a traffic matching rule
+func (nc *NetworkClient) DeleteTrafficRule(ctx context.Context, siteID, ruleID string) error {
+	nc.logger.Debugf("Deleting traffic matching rule with ID: %s", ruleID)
+	url := nc.restURL(siteID, ResourceTrafficRule, ruleID)
+	return nc.makeDeleteRequest(ctx, url)
+}
+
+// DeleteVPNTunnel deletes a VPN site-to-site tunnel
+func (nc *NetworkClient) DeleteVPNTunnel(ctx context.Context, siteID, tunnelID string) error {
+	nc.logger.Debugf("Deleting VPN site-to-site tunnel with ID: %s", tunnelID)
+	url := nc.restURL(siteID, ResourceVPNTunnel, tunnelID)
+	return nc.makeDeleteRequest(ctx, url)
+}
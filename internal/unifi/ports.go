@@ -0,0 +1,52 @@
+package unifi
+
+import (
+	"context"
+)
+
+// GetPortProfiles retrieves the switch port profiles of a site
+func (nc *NetworkClient) GetPortProfiles(ctx context.Context, siteID string) ([]NetworkPortProfile, error) {
+	nc.logger.WithField("site_id", siteID).Debug("Fetching port profiles")
+	return getList[NetworkPortProfile](ctx, nc, nc.restURL(siteID, ResourcePortProfile, ""))
+}
+
+// GetPortProfileDetailed retrieves a single switch port profile
+func (nc *NetworkClient) GetPortProfileDetailed(ctx context.Context, siteID, profileID string) (*NetworkPortProfile, error) {
+	return getObject[NetworkPortProfile](ctx, nc, nc.restURL(siteID, ResourcePortProfile, profileID))
+}
+
+// CreatePortProfile creates a new switch port profile
+func (nc *NetworkClient) CreatePortProfile(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	nc.logger.Debug("Creating new port profile")
+	return nc.makePostRequest(ctx, nc.restURL(siteID, ResourcePortProfile, ""), config)
+}
+
+// PatchPortProfile updates a switch port profile
+func (nc *NetworkClient) PatchPortProfile(ctx context.Context, siteID, profileID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	nc.logger.Debugf("Updating port profile settings for ID: %s", profileID)
+	return nc.makePatchRequest(ctx, nc.restURL(siteID, ResourcePortProfile, profileID), settings)
+}
+
+// DeletePortProfile deletes a switch port profile
+func (nc *NetworkClient) DeletePortProfile(ctx context.Context, siteID, profileID string) error {
+	nc.logger.Debugf("Deleting port profile with ID: %s", profileID)
+	return nc.makeDeleteRequest(ctx, nc.restURL(siteID, ResourcePortProfile, profileID))
+}
+
+// PatchDevice updates the configuration of an adopted device, such as its
+// port_overrides. deviceID is the device's legacy _id.
+func (nc *NetworkClient) PatchDevice(ctx context.Context, siteID, deviceID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	nc.logger.Debugf("Updating device settings for ID: %s", deviceID)
+	return nc.makePatchRequest(ctx, nc.restURL(siteID, ResourceDevice, deviceID), settings)
+}
+
+// PowerCyclePort briefly cuts PoE power to a port of the switch with the given
+// MAC address, restarting the powered device
+func (nc *NetworkClient) PowerCyclePort(ctx context.Context, siteID, mac string, portIdx int) (map[string]interface{}, error) {
+	return nc.deviceCommand(ctx, siteID, "devmgr", DeviceCommandPowerCycle, mac, map[string]interface{}{"port_idx": portIdx})
+}
+
+// PlanPowerCyclePort returns the request PowerCyclePort would send, without sending it
+func (nc *NetworkClient) PlanPowerCyclePort(siteID, mac string, portIdx int) RequestPlan {
+	return nc.planDeviceCommand(siteID, "devmgr", DeviceCommandPowerCycle, mac, map[string]interface{}{"port_idx": portIdx})
+}
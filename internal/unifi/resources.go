@@ -0,0 +1,60 @@
+package unifi
+
+import "context"
+
+// Resource names a controller REST collection under api/s/{site}/rest
+type Resource string
+
+// Resources that support write operations
+const (
+	ResourceWiFiNetwork    Resource = "networkconf"
+	ResourceFirewallZone   Resource = "firewallzone"
+	ResourceACLRule        Resource = "rule"
+	ResourceHotspotVoucher Resource = "hotspotop"
+	ResourceTrafficRule    Resource = "trafficrule"
+	ResourceVPNTunnel      Resource = "vpnserverconfig"
+	ResourcePortProfile    Resource = "portconf"
+	ResourceDevice         Resource = "device"
+)
+
+// RequestPlan describes a write request exactly as it would be sent to the controller
+type RequestPlan struct {
+	Method string                 `json:"method"`
+	URL    string                 `json:"url"`
+	Body   map[string]interface{} `json:"body,omitempty"`
+}
+
+// restURL builds the URL of a REST collection, or of a single object when id is set
+func (nc *NetworkClient) restURL(siteID string, resource Resource, id string) string {
+	url := nc.networkURL("/api/s/%s/rest/%s", siteID, resource)
+	if id != "" {
+		url += "/" + id
+	}
+	return url
+}
+
+// GetObject retrieves a single object as the controller returned it. Unlike the
+// typed Get*Detailed methods it keeps fields absent that the controller left out,
+// rather than filling them with zero values.
+func (nc *NetworkClient) GetObject(ctx context.Context, siteID string, resource Resource, id string) (map[string]interface{}, error) {
+	object, err := getObject[map[string]interface{}](ctx, nc, nc.restURL(siteID, resource, id))
+	if err != nil {
+		return nil, err
+	}
+	return *object, nil
+}
+
+// PlanPatch returns the request a Patch* call would send, without sending it
+func (nc *NetworkClient) PlanPatch(siteID string, resource Resource, id string, settings map[string]interface{}) RequestPlan {
+	return RequestPlan{Method: "PATCH", URL: nc.restURL(siteID, resource, id), Body: settings}
+}
+
+// PlanCreate returns the request a Create* call would send, without sending it
+func (nc *NetworkClient) PlanCreate(siteID string, resource Resource, config map[string]interface{}) RequestPlan {
+	return RequestPlan{Method: "POST", URL: nc.restURL(siteID, resource, ""), Body: config}
+}
+
+// PlanDelete returns the request a Delete* call would send, without sending it
+func (nc *NetworkClient) PlanDelete(siteID string, resource Resource, id string) RequestPlan {
+	return RequestPlan{Method: "DELETE", URL: nc.restURL(siteID, resource, id)}
+}
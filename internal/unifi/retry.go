@@ -0,0 +1,201 @@
+package unifi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RetryPolicy controls how requests that fail transiently are retried.
+// Transport errors, 429 Too Many Requests and 500/502/503/504 responses are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts per request, including the first; 1 disables retries
+	BaseDelay   time.Duration // backoff before the first retry, doubled for each further retry
+	MaxDelay    time.Duration // upper bound for a single wait, including Retry-After
+	RetryWrites bool          // also retry PATCH, POST and DELETE, which may not be idempotent
+}
+
+// DefaultRetryPolicy retries reads up to three times in total and never retries writes
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+}
+
+// WithRetryPolicy replaces the default retry policy
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.retry = policy
+	}
+}
+
+// RetryStats counts the retries made on behalf of one context
+type RetryStats struct {
+	retries atomic.Int64
+}
+
+// Retries returns the number of retries made so far
+func (s *RetryStats) Retries() int {
+	return int(s.retries.Load())
+}
+
+type retryStatsKey struct{}
+
+// WithRetryStats returns a context whose requests count their retries in the returned stats
+func WithRetryStats(ctx context.Context) (context.Context, *RetryStats) {
+	stats := &RetryStats{}
+	return context.WithValue(ctx, retryStatsKey{}, stats), stats
+}
+
+func retryStatsFromContext(ctx context.Context) *RetryStats {
+	stats, _ := ctx.Value(retryStatsKey{}).(*RetryStats)
+	return stats
+}
+
+// send sends req, retrying it according to the client's retry policy. Request
+// bodies are replayed through req.GetBody, which http.NewRequest sets for the
+// in-memory readers used by this package.
+func (nc *NetworkClient) send(req *http.Request) (*http.Response, error) {
+	attempts := nc.retry.MaxAttempts
+	if attempts < 1 || (!nc.retry.RetryWrites && !isIdempotent(req.Method)) {
+		attempts = 1
+	}
+
+	session := nc.currentSession()
+	reloggedIn := false
+	for attempt := 1; ; attempt++ {
+		nc.setAuthHeaders(req)
+		resp, err := nc.httpClient.Do(req)
+		nc.observeResponse(resp, err)
+
+		// An expired session is renewed once and the request sent again
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && nc.sessionAuth() && !reloggedIn {
+			reloggedIn = true
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if err := nc.relogin(req.Context(), session); err != nil {
+				return nil, fmt.Errorf("session expired and login failed: %w", err)
+			}
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+			attempt--
+			continue
+		}
+
+		if attempt >= attempts || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		wait := nc.backoff(attempt, resp)
+		fields := logrus.Fields{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"attempt": attempt,
+			"wait":    wait,
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status_code"] = resp.StatusCode
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		nc.logger.WithFields(fields).Warn("Retrying request")
+
+		if err := rewindBody(req); err != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if stats := retryStatsFromContext(req.Context()); stats != nil {
+			stats.retries.Add(1)
+		}
+	}
+}
+
+// rewindBody resets the body of req so it can be sent again
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	req.Body = body
+	return nil
+}
+
+// backoff returns how long to wait before retry number attempt: the Retry-After
+// header when the controller sent one, otherwise exponential backoff with jitter
+func (nc *NetworkClient) backoff(attempt int, resp *http.Response) time.Duration {
+	maxDelay := nc.retry.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryPolicy().MaxDelay
+	}
+
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, maxDelay)
+		}
+	}
+
+	delay := nc.retry.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	// Full jitter in [delay/2, delay) spreads retries from concurrent calls
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter accepts both forms of Retry-After: delay seconds or an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package unifi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithCredentials switches the client to session authentication: it logs in with
+// username and password, keeps the session cookie, and logs in again when the
+// session expires. This is for controllers without integration API keys. An API
+// key configured alongside is still sent.
+func WithCredentials(username, password string) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.username = username
+		nc.password = password
+	}
+}
+
+// sessionAuth reports whether the client logs in with a username and password
+func (nc *NetworkClient) sessionAuth() bool {
+	return nc.username != ""
+}
+
+// ErrAPIKeyRequired is returned for integration API requests of a client without
+// an API key: those endpoints do not accept session logins
+var ErrAPIKeyRequired = errors.New("the integration API only accepts API keys: set UNIFI_API_KEY alongside the username and password")
+
+// IntegrationAPI reports whether the client can use the integration/v1 endpoints,
+// which need an API key. Session logins only reach the legacy API.
+func (nc *NetworkClient) IntegrationAPI() bool {
+	return nc.apiKey != ""
+}
+
+// checkIntegrationAPI fails integration API requests of a client without an API
+// key before they are sent, as the controller would only answer them with 401
+func (nc *NetworkClient) checkIntegrationAPI(req *http.Request) error {
+	if nc.IntegrationAPI() || !strings.Contains(req.URL.Path, "/integration/") {
+		return nil
+	}
+	return fmt.Errorf("%w (%s %s)", ErrAPIKeyRequired, req.Method, req.URL.Path)
+}
+
+// enableSession gives the HTTP client a cookie jar for the session cookie
+func (nc *NetworkClient) enableSession() {
+	if nc.httpClient.Jar != nil {
+		return
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		// cookiejar.New only fails for an invalid public suffix list, and none is used
+		panic(err)
+	}
+	nc.httpClient.Jar = jar
+}
+
+// setAuthHeaders adds the API key and, for sessions, the CSRF token to req
+func (nc *NetworkClient) setAuthHeaders(req *http.Request) {
+	if nc.apiKey != "" {
+		req.Header.Set("X-API-KEY", nc.apiKey)
+	}
+	nc.mu.RLock()
+	csrfToken := nc.csrfToken
+	nc.mu.RUnlock()
+	if csrfToken != "" {
+		req.Header.Set("X-CSRF-Token", csrfToken)
+	}
+}
+
+// updateCSRFToken keeps the CSRF token current; UniFi OS rotates it on some responses
+func (nc *NetworkClient) updateCSRFToken(resp *http.Response) {
+	token := resp.Header.Get("X-Updated-CSRF-Token")
+	if token == "" {
+		token = resp.Header.Get("X-CSRF-Token")
+	}
+	if token == "" {
+		return
+	}
+	nc.mu.Lock()
+	nc.csrfToken = token
+	nc.mu.Unlock()
+}
+
+// loginURL returns where a controller of this candidate's type accepts logins
+func (c controllerCandidate) loginURL() string {
+	if c.controllerType == ControllerClassic {
+		return c.root + "/api/login"
+	}
+	return strings.TrimSuffix(c.root, unifiOSPrefix) + "/api/auth/login"
+}
+
+// loginAny logs in to each candidate until one accepts the login endpoint
+func (nc *NetworkClient) loginAny(ctx context.Context) (controllerCandidate, error) {
+	var lastErr error
+	for _, candidate := range nc.candidates() {
+		err := nc.login(ctx, candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if IsUnauthorized(err) || IsValidation(err) {
+			return controllerCandidate{}, fmt.Errorf("controller rejected the username or password: %w", err)
+		}
+		if ctx.Err() != nil {
+			return controllerCandidate{}, ctx.Err()
+		}
+
+		nc.logger.WithError(err).WithField("controller_type", candidate.controllerType).Debug("Controller login failed")
+		lastErr = err
+	}
+	return controllerCandidate{}, fmt.Errorf("no UniFi Network login found at %s (last error: %v)", nc.baseURL, lastErr)
+}
+
+// login starts a session with the controller described by candidate. The login
+// request bypasses do, so it is neither retried nor answered by another login.
+func (nc *NetworkClient) login(ctx context.Context, candidate controllerCandidate) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"username": nc.username,
+		"password": nc.password,
+		"remember": true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal login request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, candidate.loginURL(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := nc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("login request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(req, resp.StatusCode, resp.Header, respBody)
+	}
+
+	nc.mu.Lock()
+	nc.csrfToken = ""
+	nc.sessionGen++
+	nc.mu.Unlock()
+	nc.updateCSRFToken(resp)
+	// Classic controllers hand out the CSRF token as a cookie instead
+	if nc.csrf() == "" {
+		if u, err := url.Parse(candidate.root); err == nil {
+			for _, cookie := range nc.httpClient.Jar.Cookies(u) {
+				if cookie.Name == "csrf_token" {
+					nc.mu.Lock()
+					nc.csrfToken = cookie.Value
+					nc.mu.Unlock()
+				}
+			}
+		}
+	}
+
+	nc.logger.WithFields(logrus.Fields{
+		"controller_type": candidate.controllerType,
+		"username":        nc.username,
+	}).Info("Logged in to Unifi Network controller")
+	return nil
+}
+
+func (nc *NetworkClient) csrf() string {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return nc.csrfToken
+}
+
+// currentSession returns the session generation a request is sent with
+func (nc *NetworkClient) currentSession() int {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return nc.sessionGen
+}
+
+// relogin starts a new session after a request sent with session generation gen
+// was answered with 401. Concurrent requests that failed with the same session
+// share one login.
+func (nc *NetworkClient) relogin(ctx context.Context, gen int) error {
+	nc.loginMu.Lock()
+	defer nc.loginMu.Unlock()
+
+	nc.mu.RLock()
+	candidate := controllerCandidate{controllerType: nc.controllerType, root: nc.root}
+	renewed := nc.sessionGen != gen
+	nc.mu.RUnlock()
+	if renewed {
+		return nil
+	}
+	if candidate.controllerType == ControllerAuto {
+		candidate.controllerType = ControllerUniFiOS
+	}
+
+	nc.logger.Info("Session expired, logging in again")
+	if err := nc.login(ctx, candidate); err != nil {
+		return err
+	}
+
+	nc.mu.Lock()
+	nc.verifiedAt = time.Now()
+	nc.mu.Unlock()
+	return nil
+}
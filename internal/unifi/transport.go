@@ -0,0 +1,85 @@
+package unifi
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportConfig controls the connections the client makes to the controller
+type TransportConfig struct {
+	Proxy                 *url.URL      // outbound proxy; nil uses HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment
+	Timeout               time.Duration // whole request including reading the body; 0 means none
+	DialTimeout           time.Duration // TCP connect
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration // wait for response headers after sending the request; 0 means none
+	IdleConnTimeout       time.Duration // how long idle connections are kept in the pool
+	MaxIdleConns          int           // idle connections kept across all hosts
+	MaxIdleConnsPerHost   int           // idle connections kept per host
+	KeepAlive             time.Duration // TCP keep-alive probe interval; negative disables probes
+	DisableKeepAlives     bool          // open a new connection for every request
+}
+
+// DefaultTransportConfig matches Go's default transport, with a 30s request
+// timeout and more idle connections per host, since the client talks to one host
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		Timeout:             30 * time.Second,
+		DialTimeout:         30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		KeepAlive:           30 * time.Second,
+	}
+}
+
+// WithTransportConfig replaces the default transport configuration
+func WithTransportConfig(cfg TransportConfig) ClientOption {
+	return func(nc *NetworkClient) {
+		nc.transport = cfg
+	}
+}
+
+// ParseProxyURL parses an outbound proxy URL such as http://bastion:3128 or
+// socks5://bastion:1080; the empty string means the proxy from the environment
+func ParseProxyURL(value string) (*url.URL, error) {
+	if value == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", value)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %q (want http, https, socks5 or socks5h)", u.Scheme)
+}
+
+// newTransport builds the HTTP transport for cfg from a clone of Go's default
+// transport, so settings cfg does not cover keep their defaults
+func newTransport(cfg TransportConfig, tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != nil {
+		transport.Proxy = http.ProxyURL(cfg.Proxy)
+	}
+	transport.DialContext = (&net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: cfg.KeepAlive,
+	}).DialContext
+	transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport
+}
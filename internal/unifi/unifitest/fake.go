@@ -0,0 +1,668 @@
+// Package unifitest provides an in-memory implementation of unifi.NetworkAPI
+// for testing code built on the Network API without a controller.
+package unifitest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/surrealwolf/unifi-network-mcp/internal/unifi"
+)
+
+// Call records one method call made on a FakeNetwork
+type Call struct {
+	Method  string
+	SiteID  string
+	ID      string
+	Payload map[string]interface{}
+}
+
+// FakeNetwork is an in-memory unifi.NetworkAPI. Reads return the data in its
+// fields; writes are recorded in Calls and echo their payload back. Set an
+// entry in Errors to make a method fail. The fields may be set directly before
+// use; while in use, access Calls through CallsTo.
+type FakeNetwork struct {
+	URL            string
+	Type           unifi.ControllerType
+	ReadOnlyClient bool
+	SessionOnly    bool // acts like a client without an API key, see IntegrationAPI
+	Info           unifi.NetworkApplicationInfo
+	Health         unifi.NetworkHealthSubsystem
+
+	Sites           []unifi.NetworkSite
+	Devices         []unifi.NetworkDevice
+	DeviceDetails   []unifi.NetworkDeviceDetail
+	PendingDevices  []unifi.NetworkPendingDevice
+	DeviceTags      []unifi.NetworkDeviceTag
+	Clients         []unifi.NetworkConnectedClient
+	ClientStats     []unifi.NetworkClientDevice
+	WiFiNetworks    []unifi.NetworkWiFiNetwork
+	NetworkConfigs  []unifi.NetworkConfig
+	WiFiBroadcasts  []unifi.NetworkWiFiBroadcast
+	FirewallZones   []unifi.NetworkFirewallZone
+	ACLRules        []unifi.NetworkACLRule
+	HotspotVouchers []unifi.NetworkHotspotVoucher
+	TrafficRules    []unifi.NetworkTrafficRule
+	VPNServers      []unifi.NetworkVPNServer
+	VPNTunnels      []unifi.NetworkVPNTunnel
+	WANConfig       []unifi.NetworkWANConfig
+	RADIUSProfiles  []unifi.NetworkRADIUSProfile
+	DPICategories   []unifi.NetworkDPICategory
+	DPIApplications []unifi.NetworkDPIApplication
+	PortProfiles    []unifi.NetworkPortProfile
+
+	// Objects holds the raw REST objects GetObject returns, by resource
+	Objects map[unifi.Resource][]map[string]interface{}
+
+	// Firmware, see UpgradeDevice
+	AvailableFirmware []unifi.NetworkFirmware
+	DeviceFirmware    []unifi.NetworkDeviceFirmware
+
+	// Errors makes the named method, such as "GetDevices", return the error
+	Errors map[string]error
+
+	mu      sync.Mutex
+	Calls   []Call
+	created int
+}
+
+// NewFakeNetwork returns a fake controller with one site named "default"
+func NewFakeNetwork() *FakeNetwork {
+	return &FakeNetwork{
+		URL:  "https://unifi.test",
+		Type: unifi.ControllerUniFiOS,
+		Info: unifi.NetworkApplicationInfo{ApplicationVersion: "9.0.114"},
+		Sites: []unifi.NetworkSite{
+			{ID: "site-1", Name: "default", Desc: "Default", ExternalID: "site-uuid-1"},
+		},
+		Errors: map[string]error{},
+	}
+}
+
+// CallsTo returns the recorded calls of method
+func (f *FakeNetwork) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []Call
+	for _, call := range f.Calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// record notes a call and returns the error configured for its method
+func (f *FakeNetwork) record(method, siteID, id string, payload map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, Call{Method: method, SiteID: siteID, ID: id, Payload: payload})
+	return f.Errors[method]
+}
+
+// write records a write and returns the object the controller would answer with
+func (f *FakeNetwork) write(method, siteID, id string, payload map[string]interface{}) (map[string]interface{}, error) {
+	if err := f.record(method, siteID, id, payload); err != nil {
+		return nil, err
+	}
+	if f.ReadOnlyClient {
+		return nil, unifi.ErrReadOnly
+	}
+
+	f.mu.Lock()
+	if id == "" {
+		f.created++
+		id = fmt.Sprintf("created-%d", f.created)
+	}
+	f.mu.Unlock()
+
+	object := map[string]interface{}{"_id": id}
+	for key, value := range payload {
+		object[key] = value
+	}
+	return object, nil
+}
+
+// find returns the item of items whose ID is id, or a not-found APIError
+func find[T any](items []T, idOf func(T) string, id, what string) (*T, error) {
+	for i := range items {
+		if idOf(items[i]) == id {
+			return &items[i], nil
+		}
+	}
+	return nil, &unifi.APIError{
+		StatusCode: http.StatusNotFound,
+		Method:     http.MethodGet,
+		Code:       "api.err.NotFound",
+		Message:    fmt.Sprintf("%s %s not found", what, id),
+	}
+}
+
+// BaseURL returns the fake controller URL
+func (f *FakeNetwork) BaseURL() string { return f.URL }
+
+// ControllerType returns the fake controller type
+func (f *FakeNetwork) ControllerType() unifi.ControllerType { return f.Type }
+
+// ReadOnly reports whether writes fail with unifi.ErrReadOnly
+func (f *FakeNetwork) ReadOnly() bool { return f.ReadOnlyClient }
+
+// IntegrationAPI reports whether the fake has an API key for the integration API
+func (f *FakeNetwork) IntegrationAPI() bool { return !f.SessionOnly }
+
+// Authenticate succeeds unless an error is configured
+func (f *FakeNetwork) Authenticate(ctx context.Context) error {
+	return f.record("Authenticate", "", "", nil)
+}
+
+// CheckEndpointHealth reports the fake endpoint as healthy
+func (f *FakeNetwork) CheckEndpointHealth(ctx context.Context) (map[string]interface{}, error) {
+	if err := f.record("CheckEndpointHealth", "", "", nil); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"status": "healthy", "code": http.StatusOK, "version": f.Info.ApplicationVersion}, nil
+}
+
+// GetInfo returns Info
+func (f *FakeNetwork) GetInfo(ctx context.Context) (*unifi.NetworkApplicationInfo, error) {
+	if err := f.record("GetInfo", "", "", nil); err != nil {
+		return nil, err
+	}
+	info := f.Info
+	return &info, nil
+}
+
+// GetSites returns Sites
+func (f *FakeNetwork) GetSites(ctx context.Context) ([]unifi.NetworkSite, error) {
+	return f.Sites, f.record("GetSites", "", "", nil)
+}
+
+// GetHealth returns Health
+func (f *FakeNetwork) GetHealth(ctx context.Context, siteID string) (*unifi.NetworkHealthSubsystem, error) {
+	if err := f.record("GetHealth", siteID, "", nil); err != nil {
+		return nil, err
+	}
+	health := f.Health
+	return &health, nil
+}
+
+// GetDevices returns Devices
+func (f *FakeNetwork) GetDevices(ctx context.Context, siteID string) ([]unifi.NetworkDevice, error) {
+	return f.Devices, f.record("GetDevices", siteID, "", nil)
+}
+
+// GetDeviceDetailed returns the entry of DeviceDetails with ID or MAC address
+// deviceID, or else the summary of the matching device of Devices
+func (f *FakeNetwork) GetDeviceDetailed(ctx context.Context, site unifi.NetworkSite, deviceID string) (*unifi.NetworkDeviceDetail, error) {
+	if err := f.record("GetDeviceDetailed", site.Name, deviceID, nil); err != nil {
+		return nil, err
+	}
+	for i := range f.DeviceDetails {
+		if detail := f.DeviceDetails[i]; detail.ID == deviceID || strings.EqualFold(detail.MAC, deviceID) {
+			return &detail, nil
+		}
+	}
+	device, err := find(f.Devices, func(d unifi.NetworkDevice) string { return d.ID }, deviceID, "device")
+	if err != nil {
+		return nil, err
+	}
+	detail := &unifi.NetworkDeviceDetail{
+		ID: device.ID, Name: device.Name, Type: device.Type, Model: device.Model, MAC: device.MAC, IP: device.IP,
+		LastSeen: device.LastSeen, Uptime: device.Uptime,
+	}
+	if device.Connected {
+		detail.State = unifi.DeviceStateConnected
+	}
+	return detail, nil
+}
+
+// GetDeviceStats returns the device of Devices with ID deviceID
+func (f *FakeNetwork) GetDeviceStats(ctx context.Context, siteID, deviceID string) (*unifi.NetworkDevice, error) {
+	if err := f.record("GetDeviceStats", siteID, deviceID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.Devices, func(d unifi.NetworkDevice) string { return d.ID }, deviceID, "device")
+}
+
+// GetPendingDevices returns PendingDevices
+func (f *FakeNetwork) GetPendingDevices(ctx context.Context) ([]unifi.NetworkPendingDevice, error) {
+	return f.PendingDevices, f.record("GetPendingDevices", "", "", nil)
+}
+
+// GetDeviceTags returns DeviceTags
+func (f *FakeNetwork) GetDeviceTags(ctx context.Context, siteID string) ([]unifi.NetworkDeviceTag, error) {
+	return f.DeviceTags, f.record("GetDeviceTags", siteID, "", nil)
+}
+
+// GetClients returns the page of Clients selected by limit and offset
+func (f *FakeNetwork) GetClients(ctx context.Context, siteID string, limit, offset int) ([]unifi.NetworkConnectedClient, error) {
+	if err := f.record("GetClients", siteID, "", nil); err != nil {
+		return nil, err
+	}
+	clients := f.Clients
+	if offset > 0 {
+		clients = clients[min(offset, len(clients)):]
+	}
+	if limit > 0 {
+		clients = clients[:min(limit, len(clients))]
+	}
+	return clients, nil
+}
+
+// GetClientDetailed returns the client of Clients with MAC address clientMAC
+func (f *FakeNetwork) GetClientDetailed(ctx context.Context, siteID, clientMAC string) (*unifi.NetworkConnectedClient, error) {
+	if err := f.record("GetClientDetailed", siteID, clientMAC, nil); err != nil {
+		return nil, err
+	}
+	return find(f.Clients, func(c unifi.NetworkConnectedClient) string { return c.MACAddress }, clientMAC, "client")
+}
+
+// GetClientStats returns ClientStats
+func (f *FakeNetwork) GetClientStats(ctx context.Context, siteID string) ([]unifi.NetworkClientDevice, error) {
+	return f.ClientStats, f.record("GetClientStats", siteID, "", nil)
+}
+
+// GetWiFiNetworks returns WiFiNetworks
+func (f *FakeNetwork) GetWiFiNetworks(ctx context.Context, siteID string) ([]unifi.NetworkWiFiNetwork, error) {
+	return f.WiFiNetworks, f.record("GetWiFiNetworks", siteID, "", nil)
+}
+
+// GetWiFiNetworkDetailed returns the network of NetworkConfigs with ID networkID
+func (f *FakeNetwork) GetWiFiNetworkDetailed(ctx context.Context, siteID, networkID string) (*unifi.NetworkConfig, error) {
+	if err := f.record("GetWiFiNetworkDetailed", siteID, networkID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.NetworkConfigs, func(n unifi.NetworkConfig) string { return n.ID }, networkID, "network")
+}
+
+// GetWiFiBroadcasts returns WiFiBroadcasts
+func (f *FakeNetwork) GetWiFiBroadcasts(ctx context.Context, siteID string) ([]unifi.NetworkWiFiBroadcast, error) {
+	return f.WiFiBroadcasts, f.record("GetWiFiBroadcasts", siteID, "", nil)
+}
+
+// GetFirewallZones returns FirewallZones
+func (f *FakeNetwork) GetFirewallZones(ctx context.Context, siteID string) ([]unifi.NetworkFirewallZone, error) {
+	return f.FirewallZones, f.record("GetFirewallZones", siteID, "", nil)
+}
+
+// GetFirewallZoneDetailed returns the zone of FirewallZones with ID zoneID
+func (f *FakeNetwork) GetFirewallZoneDetailed(ctx context.Context, siteID, zoneID string) (*unifi.NetworkFirewallZone, error) {
+	if err := f.record("GetFirewallZoneDetailed", siteID, zoneID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.FirewallZones, func(z unifi.NetworkFirewallZone) string { return z.ID }, zoneID, "firewall zone")
+}
+
+// GetACLRules returns ACLRules
+func (f *FakeNetwork) GetACLRules(ctx context.Context, siteID string) ([]unifi.NetworkACLRule, error) {
+	return f.ACLRules, f.record("GetACLRules", siteID, "", nil)
+}
+
+// GetACLRuleDetailed returns the rule of ACLRules with ID ruleID
+func (f *FakeNetwork) GetACLRuleDetailed(ctx context.Context, siteID, ruleID string) (*unifi.NetworkACLRule, error) {
+	if err := f.record("GetACLRuleDetailed", siteID, ruleID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.ACLRules, func(r unifi.NetworkACLRule) string { return r.ID }, ruleID, "ACL rule")
+}
+
+// GetHotspotVouchers returns HotspotVouchers
+func (f *FakeNetwork) GetHotspotVouchers(ctx context.Context, siteID string) ([]unifi.NetworkHotspotVoucher, error) {
+	return f.HotspotVouchers, f.record("GetHotspotVouchers", siteID, "", nil)
+}
+
+// GetHotspotVoucherDetailed returns the voucher of HotspotVouchers with ID voucherID
+func (f *FakeNetwork) GetHotspotVoucherDetailed(ctx context.Context, siteID, voucherID string) (*unifi.NetworkHotspotVoucher, error) {
+	if err := f.record("GetHotspotVoucherDetailed", siteID, voucherID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.HotspotVouchers, func(v unifi.NetworkHotspotVoucher) string { return v.ID }, voucherID, "hotspot voucher")
+}
+
+// GetTrafficRules returns TrafficRules
+func (f *FakeNetwork) GetTrafficRules(ctx context.Context, siteID string) ([]unifi.NetworkTrafficRule, error) {
+	return f.TrafficRules, f.record("GetTrafficRules", siteID, "", nil)
+}
+
+// GetTrafficRuleDetailed returns the rule of TrafficRules with ID ruleID
+func (f *FakeNetwork) GetTrafficRuleDetailed(ctx context.Context, siteID, ruleID string) (*unifi.NetworkTrafficRule, error) {
+	if err := f.record("GetTrafficRuleDetailed", siteID, ruleID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.TrafficRules, func(r unifi.NetworkTrafficRule) string { return r.ID }, ruleID, "traffic rule")
+}
+
+// GetPortProfiles returns PortProfiles
+func (f *FakeNetwork) GetPortProfiles(ctx context.Context, siteID string) ([]unifi.NetworkPortProfile, error) {
+	return f.PortProfiles, f.record("GetPortProfiles", siteID, "", nil)
+}
+
+// GetPortProfileDetailed returns the profile of PortProfiles with ID profileID
+func (f *FakeNetwork) GetPortProfileDetailed(ctx context.Context, siteID, profileID string) (*unifi.NetworkPortProfile, error) {
+	if err := f.record("GetPortProfileDetailed", siteID, profileID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.PortProfiles, func(p unifi.NetworkPortProfile) string { return p.ID }, profileID, "port profile")
+}
+
+// GetObject returns the object of Objects[resource] whose _id is id
+func (f *FakeNetwork) GetObject(ctx context.Context, siteID string, resource unifi.Resource, id string) (map[string]interface{}, error) {
+	if err := f.record("GetObject", siteID, id, nil); err != nil {
+		return nil, err
+	}
+	object, err := find(f.Objects[resource], func(o map[string]interface{}) string { id, _ := o["_id"].(string); return id }, id, string(resource))
+	if err != nil {
+		return nil, err
+	}
+	return *object, nil
+}
+
+// GetVPNServers returns VPNServers
+func (f *FakeNetwork) GetVPNServers(ctx context.Context, siteID string) ([]unifi.NetworkVPNServer, error) {
+	return f.VPNServers, f.record("GetVPNServers", siteID, "", nil)
+}
+
+// GetVPNTunnels returns VPNTunnels
+func (f *FakeNetwork) GetVPNTunnels(ctx context.Context, siteID string) ([]unifi.NetworkVPNTunnel, error) {
+	return f.VPNTunnels, f.record("GetVPNTunnels", siteID, "", nil)
+}
+
+// GetVPNTunnelDetailed returns the tunnel of VPNTunnels with ID tunnelID
+func (f *FakeNetwork) GetVPNTunnelDetailed(ctx context.Context, siteID, tunnelID string) (*unifi.NetworkVPNTunnel, error) {
+	if err := f.record("GetVPNTunnelDetailed", siteID, tunnelID, nil); err != nil {
+		return nil, err
+	}
+	return find(f.VPNTunnels, func(t unifi.NetworkVPNTunnel) string { return t.ID }, tunnelID, "VPN tunnel")
+}
+
+// GetWANConfig returns WANConfig
+func (f *FakeNetwork) GetWANConfig(ctx context.Context, siteID string) ([]unifi.NetworkWANConfig, error) {
+	return f.WANConfig, f.record("GetWANConfig", siteID, "", nil)
+}
+
+// GetRADIUSProfiles returns RADIUSProfiles
+func (f *FakeNetwork) GetRADIUSProfiles(ctx context.Context, siteID string) ([]unifi.NetworkRADIUSProfile, error) {
+	return f.RADIUSProfiles, f.record("GetRADIUSProfiles", siteID, "", nil)
+}
+
+// GetDPICategories returns DPICategories
+func (f *FakeNetwork) GetDPICategories(ctx context.Context) ([]unifi.NetworkDPICategory, error) {
+	return f.DPICategories, f.record("GetDPICategories", "", "", nil)
+}
+
+// GetDPIApplications returns DPIApplications
+func (f *FakeNetwork) GetDPIApplications(ctx context.Context) ([]unifi.NetworkDPIApplication, error) {
+	return f.DPIApplications, f.record("GetDPIApplications", "", "", nil)
+}
+
+// GetAvailableFirmware returns AvailableFirmware
+func (f *FakeNetwork) GetAvailableFirmware(ctx context.Context, siteID string) ([]unifi.NetworkFirmware, error) {
+	return f.AvailableFirmware, f.record("GetAvailableFirmware", siteID, "", nil)
+}
+
+// GetDeviceFirmware returns a copy of DeviceFirmware
+func (f *FakeNetwork) GetDeviceFirmware(ctx context.Context, siteID string) ([]unifi.NetworkDeviceFirmware, error) {
+	if err := f.record("GetDeviceFirmware", siteID, "", nil); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]unifi.NetworkDeviceFirmware{}, f.DeviceFirmware...), nil
+}
+
+// PatchWiFiNetwork records the patch and echoes settings
+func (f *FakeNetwork) PatchWiFiNetwork(ctx context.Context, siteID, networkID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("PatchWiFiNetwork", siteID, networkID, settings)
+}
+
+// PatchFirewallZone records the patch and echoes settings
+func (f *FakeNetwork) PatchFirewallZone(ctx context.Context, siteID, zoneID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("PatchFirewallZone", siteID, zoneID, settings)
+}
+
+// PatchACLRule records the patch and echoes settings
+func (f *FakeNetwork) PatchACLRule(ctx context.Context, siteID, ruleID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("PatchACLRule", siteID, ruleID, settings)
+}
+
+// PatchHotspotVoucher records the patch and echoes settings
+func (f *FakeNetwork) PatchHotspotVoucher(ctx context.Context, siteID, voucherID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("PatchHotspotVoucher", siteID, voucherID, settings)
+}
+
+// PatchTrafficRule records the patch and echoes settings
+func (f *FakeNetwork) PatchTrafficRule(ctx context.Context, siteID, ruleID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("PatchTrafficRule", siteID, ruleID, settings)
+}
+
+// PatchPortProfile records the patch and echoes settings
+func (f *FakeNetwork) PatchPortProfile(ctx context.Context, siteID, profileID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("PatchPortProfile", siteID, profileID, settings)
+}
+
+// PatchDevice records the patch and echoes settings
+func (f *FakeNetwork) PatchDevice(ctx context.Context, siteID, deviceID string, settings map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("PatchDevice", siteID, deviceID, settings)
+}
+
+// CreateWiFiNetwork records the creation and echoes config with a new ID
+func (f *FakeNetwork) CreateWiFiNetwork(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("CreateWiFiNetwork", siteID, "", config)
+}
+
+// CreateFirewallZone records the creation and echoes config with a new ID
+func (f *FakeNetwork) CreateFirewallZone(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("CreateFirewallZone", siteID, "", config)
+}
+
+// CreateACLRule records the creation and echoes config with a new ID
+func (f *FakeNetwork) CreateACLRule(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("CreateACLRule", siteID, "", config)
+}
+
+// CreateHotspotVoucher records the creation and echoes config with a new ID
+func (f *FakeNetwork) CreateHotspotVoucher(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("CreateHotspotVoucher", siteID, "", config)
+}
+
+// CreateTrafficRule records the creation and echoes config with a new ID
+func (f *FakeNetwork) CreateTrafficRule(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("CreateTrafficRule", siteID, "", config)
+}
+
+// CreateVPNTunnel records the creation and echoes config with a new ID
+func (f *FakeNetwork) CreateVPNTunnel(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("CreateVPNTunnel", siteID, "", config)
+}
+
+// CreatePortProfile records the creation and echoes config with a new ID
+func (f *FakeNetwork) CreatePortProfile(ctx context.Context, siteID string, config map[string]interface{}) (map[string]interface{}, error) {
+	return f.write("CreatePortProfile", siteID, "", config)
+}
+
+// DeleteWiFiNetwork records the deletion
+func (f *FakeNetwork) DeleteWiFiNetwork(ctx context.Context, siteID, networkID string) error {
+	_, err := f.write("DeleteWiFiNetwork", siteID, networkID, nil)
+	return err
+}
+
+// DeleteFirewallZone records the deletion
+func (f *FakeNetwork) DeleteFirewallZone(ctx context.Context, siteID, zoneID string) error {
+	_, err := f.write("DeleteFirewallZone", siteID, zoneID, nil)
+	return err
+}
+
+// DeleteACLRule records the deletion
+func (f *FakeNetwork) DeleteACLRule(ctx context.Context, siteID, ruleID string) error {
+	_, err := f.write("DeleteACLRule", siteID, ruleID, nil)
+	return err
+}
+
+// DeleteHotspotVoucher records the deletion
+func (f *FakeNetwork) DeleteHotspotVoucher(ctx context.Context, siteID, voucherID string) error {
+	_, err := f.write("DeleteHotspotVoucher", siteID, voucherID, nil)
+	return err
+}
+
+// DeleteTrafficRule records the deletion
+func (f *FakeNetwork) DeleteTrafficRule(ctx context.Context, siteID, ruleID string) error {
+	_, err := f.write("DeleteTrafficRule", siteID, ruleID, nil)
+	return err
+}
+
+// DeleteVPNTunnel records the deletion
+func (f *FakeNetwork) DeleteVPNTunnel(ctx context.Context, siteID, tunnelID string) error {
+	_, err := f.write("DeleteVPNTunnel", siteID, tunnelID, nil)
+	return err
+}
+
+// DeletePortProfile records the deletion
+func (f *FakeNetwork) DeletePortProfile(ctx context.Context, siteID, profileID string) error {
+	_, err := f.write("DeletePortProfile", siteID, profileID, nil)
+	return err
+}
+
+// command records a device or client command and returns its empty acknowledgement
+func (f *FakeNetwork) command(method, siteID, mac string, payload map[string]interface{}) (map[string]interface{}, error) {
+	if err := f.record(method, siteID, mac, payload); err != nil {
+		return nil, err
+	}
+	if f.ReadOnlyClient {
+		return nil, unifi.ErrReadOnly
+	}
+	return map[string]interface{}{}, nil
+}
+
+// RestartDevice records the restart
+func (f *FakeNetwork) RestartDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("RestartDevice", siteID, mac, nil)
+}
+
+// LocateDevice records the locate toggle
+func (f *FakeNetwork) LocateDevice(ctx context.Context, siteID, mac string, enabled bool) (map[string]interface{}, error) {
+	return f.command("LocateDevice", siteID, mac, map[string]interface{}{"enabled": enabled})
+}
+
+// ForceProvisionDevice records the provisioning
+func (f *FakeNetwork) ForceProvisionDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("ForceProvisionDevice", siteID, mac, nil)
+}
+
+// AdoptDevice records the adoption
+func (f *FakeNetwork) AdoptDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("AdoptDevice", siteID, mac, nil)
+}
+
+// ForgetDevice records the removal
+func (f *FakeNetwork) ForgetDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("ForgetDevice", siteID, mac, nil)
+}
+
+// UpgradeDevice records the upgrade and moves the device's entry in
+// DeviceFirmware to the version it was offered
+func (f *FakeNetwork) UpgradeDevice(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	ack, err := f.command("UpgradeDevice", siteID, mac, nil)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.DeviceFirmware {
+		if device := &f.DeviceFirmware[i]; strings.EqualFold(device.MAC, mac) && device.Upgradable {
+			device.Version = device.UpgradeToFirmware
+			device.Upgradable = false
+		}
+	}
+	return ack, nil
+}
+
+// PowerCyclePort records the power cycle
+func (f *FakeNetwork) PowerCyclePort(ctx context.Context, siteID, mac string, portIdx int) (map[string]interface{}, error) {
+	return f.command("PowerCyclePort", siteID, mac, map[string]interface{}{"port_idx": portIdx})
+}
+
+// BlockClient records the block
+func (f *FakeNetwork) BlockClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("BlockClient", siteID, mac, nil)
+}
+
+// UnblockClient records the unblock
+func (f *FakeNetwork) UnblockClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("UnblockClient", siteID, mac, nil)
+}
+
+// KickClient records the disconnect
+func (f *FakeNetwork) KickClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("KickClient", siteID, mac, nil)
+}
+
+// ForgetClient records the removal
+func (f *FakeNetwork) ForgetClient(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("ForgetClient", siteID, mac, nil)
+}
+
+// AuthorizeGuest records the authorization with its limits
+func (f *FakeNetwork) AuthorizeGuest(ctx context.Context, siteID, mac string, auth unifi.GuestAuthorization) (map[string]interface{}, error) {
+	return f.command("AuthorizeGuest", siteID, mac, map[string]interface{}{
+		"minutes": auth.Minutes, "up": auth.UpKbps, "down": auth.DownKbps, "bytes": auth.MBytes, "ap_mac": auth.APMAC,
+	})
+}
+
+// UnauthorizeGuest records the revocation
+func (f *FakeNetwork) UnauthorizeGuest(ctx context.Context, siteID, mac string) (map[string]interface{}, error) {
+	return f.command("UnauthorizeGuest", siteID, mac, nil)
+}
+
+// restURL mirrors the legacy REST URLs of NetworkClient for request plans
+func (f *FakeNetwork) restURL(siteID string, resource unifi.Resource, id string) string {
+	url := fmt.Sprintf("%s/proxy/network/api/s/%s/rest/%s", f.URL, siteID, resource)
+	if id != "" {
+		url += "/" + id
+	}
+	return url
+}
+
+// PlanPatch returns the request a patch would send
+func (f *FakeNetwork) PlanPatch(siteID string, resource unifi.Resource, id string, settings map[string]interface{}) unifi.RequestPlan {
+	return unifi.RequestPlan{Method: "PATCH", URL: f.restURL(siteID, resource, id), Body: settings}
+}
+
+// PlanCreate returns the request a creation would send
+func (f *FakeNetwork) PlanCreate(siteID string, resource unifi.Resource, config map[string]interface{}) unifi.RequestPlan {
+	return unifi.RequestPlan{Method: "POST", URL: f.restURL(siteID, resource, ""), Body: config}
+}
+
+// PlanDelete returns the request a deletion would send
+func (f *FakeNetwork) PlanDelete(siteID string, resource unifi.Resource, id string) unifi.RequestPlan {
+	return unifi.RequestPlan{Method: "DELETE", URL: f.restURL(siteID, resource, id)}
+}
+
+// PlanPowerCyclePort returns the request a power cycle would send
+func (f *FakeNetwork) PlanPowerCyclePort(siteID, mac string, portIdx int) unifi.RequestPlan {
+	return unifi.RequestPlan{
+		Method: "POST",
+		URL:    fmt.Sprintf("%s/proxy/network/api/s/%s/cmd/devmgr", f.URL, siteID),
+		Body:   map[string]interface{}{"cmd": unifi.DeviceCommandPowerCycle, "mac": strings.ToLower(mac), "port_idx": portIdx},
+	}
+}
+
+// PlanRestartDevice returns the request a restart would send
+func (f *FakeNetwork) PlanRestartDevice(siteID, mac string) unifi.RequestPlan {
+	return unifi.RequestPlan{
+		Method: "POST",
+		URL:    fmt.Sprintf("%s/proxy/network/api/s/%s/cmd/devmgr", f.URL, siteID),
+		Body:   map[string]interface{}{"cmd": unifi.DeviceCommandRestart, "mac": strings.ToLower(mac), "reboot_type": "soft"},
+	}
+}
+
+// PlanForgetDevice returns the request forgetting a device would send
+func (f *FakeNetwork) PlanForgetDevice(siteID, mac string) unifi.RequestPlan {
+	return unifi.RequestPlan{
+		Method: "POST",
+		URL:    fmt.Sprintf("%s/proxy/network/api/s/%s/cmd/sitemgr", f.URL, siteID),
+		Body:   map[string]interface{}{"cmd": unifi.DeviceCommandForget, "macs": []string{strings.ToLower(mac)}},
+	}
+}